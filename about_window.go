@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"image/color"
 
 	"gioui.org/app"
 	"gioui.org/layout"
@@ -12,6 +11,8 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+
+	"gocalc/i18n"
 )
 
 var (
@@ -27,16 +28,16 @@ type AboutWindow struct {
 	closeBtn    widget.Clickable // 右上角关闭按钮
 	closeBtnBot widget.Clickable // 底部关闭按钮
 	scrollView  widget.List
+	locale      i18n.Locale
+	palette     Theme
 }
 
-func NewAboutWindow() *AboutWindow {
-	theme := material.NewTheme()
-	theme.Palette.Fg = white
-	theme.Palette.Bg = darkGreen
-
+func NewAboutWindow(locale i18n.Locale, palette Theme) *AboutWindow {
 	return &AboutWindow{
-		theme:      theme,
+		theme:      palette.material(),
+		palette:    palette,
 		scrollView: widget.List{List: layout.List{Axis: layout.Vertical}},
+		locale:     locale,
 	}
 }
 
@@ -64,9 +65,8 @@ func (a *AboutWindow) Run(w *app.Window) error {
 }
 
 func (a *AboutWindow) Layout(gtx layout.Context) layout.Dimensions {
-	// 填充深绿色背景
-	dialogBg := color.NRGBA{R: 45, G: 75, B: 65, A: 255}
-	paint.Fill(gtx.Ops, dialogBg)
+	// 填充主题的对话框背景
+	paint.Fill(gtx.Ops, a.palette.Dialog)
 
 	return layout.Flex{
 		Axis:    layout.Vertical,
@@ -103,8 +103,8 @@ func (a *AboutWindow) layoutTitleBar(gtx layout.Context) layout.Dimensions {
 		}.Layout(gtx,
 			// 标题
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				label := material.H6(a.theme, "About")
-				label.Color = white
+				label := material.H6(a.theme, a.locale.T("About"))
+				label.Color = a.palette.Text
 				label.Alignment = text.Start
 				return label.Layout(gtx)
 			}),
@@ -125,14 +125,14 @@ func (a *AboutWindow) layoutContent(gtx layout.Context) layout.Dimensions {
 						Axis: layout.Horizontal,
 					}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							label := material.Body1(a.theme, "Author: ")
-							label.Color = lightGray
+							label := material.Body1(a.theme, a.locale.T("Author: "))
+							label.Color = a.palette.Secondary
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							label := material.Body1(a.theme, "panyingyun@gmail.com")
-							label.Color = white
+							label.Color = a.palette.Text
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
@@ -146,14 +146,14 @@ func (a *AboutWindow) layoutContent(gtx layout.Context) layout.Dimensions {
 						Axis: layout.Horizontal,
 					}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							label := material.Body1(a.theme, "Purpose: ")
-							label.Color = lightGray
+							label := material.Body1(a.theme, a.locale.T("Purpose: "))
+							label.Color = a.palette.Secondary
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							label := material.Body1(a.theme, "How to write Go GUI app with gio")
-							label.Color = white
+							label := material.Body1(a.theme, a.locale.T("How to write Go GUI app with gio"))
+							label.Color = a.palette.Text
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
@@ -168,14 +168,14 @@ func (a *AboutWindow) layoutContent(gtx layout.Context) layout.Dimensions {
 						Axis: layout.Horizontal,
 					}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							label := material.Body1(a.theme, "Version: ")
-							label.Color = lightGray
+							label := material.Body1(a.theme, a.locale.T("Version: "))
+							label.Color = a.palette.Secondary
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							label := material.Body1(a.theme, Version)
-							label.Color = white
+							label.Color = a.palette.Text
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
@@ -190,14 +190,14 @@ func (a *AboutWindow) layoutContent(gtx layout.Context) layout.Dimensions {
 						Axis: layout.Horizontal,
 					}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							label := material.Body1(a.theme, "GitCommit: ")
-							label.Color = lightGray
+							label := material.Body1(a.theme, a.locale.T("GitCommit: "))
+							label.Color = a.palette.Secondary
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							label := material.Body1(a.theme, GitCommit)
-							label.Color = white
+							label.Color = a.palette.Text
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
@@ -212,14 +212,14 @@ func (a *AboutWindow) layoutContent(gtx layout.Context) layout.Dimensions {
 						Axis: layout.Horizontal,
 					}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							label := material.Body1(a.theme, "BuildTime: ")
-							label.Color = lightGray
+							label := material.Body1(a.theme, a.locale.T("BuildTime: "))
+							label.Color = a.palette.Secondary
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							label := material.Body1(a.theme, BuildTime)
-							label.Color = white
+							label.Color = a.palette.Text
 							label.TextSize = unit.Sp(14)
 							return label.Layout(gtx)
 						}),
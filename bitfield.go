@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strconv"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// bitRows 位面板的行数，每行 16 位、分为 4 组
+const bitRows = 4
+
+// layoutBitField 程序员模式下的 64 位位面板，从高位到低位排列，点击翻转对应的位
+func (c *Calculator) layoutBitField(gtx layout.Context) layout.Dimensions {
+	rows := make([]layout.FlexChild, bitRows)
+	for r := range rows {
+		rows[r] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			high := 63 - r*16
+			return layout.Flex{
+				Axis:    layout.Horizontal,
+				Spacing: layout.SpaceBetween,
+			}.Layout(gtx,
+				c.nibble(high),
+				c.nibble(high-4),
+				c.nibble(high-8),
+				c.nibble(high-12),
+			)
+		})
+	}
+	return layout.Inset{
+		Left:   unit.Dp(30),
+		Right:  unit.Dp(30),
+		Bottom: unit.Dp(5),
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+	})
+}
+
+// nibble 以 high 为最高位的 4 位一组，下方标注该组最低位的序号
+func (c *Calculator) nibble(high int) layout.FlexChild {
+	return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+		bits := make([]layout.FlexChild, 4)
+		for i := range bits {
+			bits[i] = layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return c.bit(gtx, high-i)
+			})
+		}
+		return layout.Inset{Left: unit.Dp(4), Right: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, bits...)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					label := material.Caption(c.theme, strconv.Itoa(high-3))
+					label.Color = c.palette.Disabled
+					label.Alignment = text.End
+					label.TextSize = unit.Sp(9)
+					return label.Layout(gtx)
+				}),
+			)
+		})
+	})
+}
+
+// bit 单个位，1 高亮显示，超出字长的位变暗且不可点击
+func (c *Calculator) bit(gtx layout.Context, i int) layout.Dimensions {
+	value := "0"
+	color := c.palette.Text
+	if c.state.Bits&(1<<uint(i)) != 0 {
+		value = "1"
+		color = c.palette.Accent
+	}
+	if i >= c.state.Word.Bits {
+		color = c.palette.Disabled
+	}
+	return c.bitBtns[i].Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		label := material.Body2(c.theme, value)
+		label.Color = color
+		label.Alignment = text.Middle
+		label.TextSize = unit.Sp(14)
+		return label.Layout(gtx)
+	})
+}
+
+// handleBitEvents 处理位面板的点击，翻转对应的位
+func (c *Calculator) handleBitEvents(gtx layout.Context) {
+	for i := range c.bitBtns {
+		if c.bitBtns[i].Clicked(gtx) && c.keypad == keypadProgrammer {
+			c.state = c.engine.ToggleBit(i)
+			c.invalidate()
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gocalc/engine"
+)
+
+// 命令行模式的退出码
+const (
+	exitOK    = 0 // 所有表达式都计算成功
+	exitError = 1 // 有表达式无法解析或计算
+	exitUsage = 2 // 参数错误
+)
+
+// cli 命令行模式：计算 -e 给出的或标准输入中每行的表达式，不打开窗口
+type cli struct {
+	exprs   []string
+	config  engine.Config
+	base    int           // 输出进制
+	format  string        // 输出格式，见 outputFormats
+	display engine.Format // grouped 和 display 使用与图形界面相同的数字格式，来自设置文件和语言区域
+}
+
+// outputFormats 命令行 -format 可用的输出格式
+var outputFormats = []string{"plain", "grouped", "display", "sci"}
+
+// parseCLI 解析命令行参数；没有参数且标准输入不是管道或文件时返回 false，启动图形界面
+func parseCLI(args []string, stdin *os.File, stderr io.Writer) (*cli, bool, error) {
+	if len(args) == 0 && !redirected(stdin) {
+		return nil, false, nil
+	}
+	c := &cli{config: engine.DefaultConfig}
+	var angle string
+	fs := flag.NewFlagSet("gocalc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gocalc [flags]\n"+
+			"  Without flags the calculator window opens. With -e, or with expressions\n"+
+			"  piped to stdin (one per line), results are printed instead.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Func("e", "evaluate `expression` (may be repeated)", func(s string) error {
+		c.exprs = append(c.exprs, s)
+		return nil
+	})
+	fs.IntVar(&c.config.Precision, "precision", engine.DefaultPrecision, "significant `digits` kept by division and functions")
+	fs.IntVar(&c.base, "base", 10, "output `base`: 2, 8, 10 or 16; results must be integers unless 10")
+	fs.StringVar(&c.format, "format", "plain", "number `format`: plain (full precision), grouped (with separators),\ndisplay (as shown in the window) or sci (scientific notation)")
+	fs.StringVar(&angle, "angle", "deg", "angle unit of trigonometric functions: deg, rad or grad")
+	if err := fs.Parse(args); err != nil {
+		return nil, true, err
+	}
+	if fs.NArg() > 0 {
+		return nil, true, fmt.Errorf("unexpected argument %q, use -e to evaluate an expression", fs.Arg(0))
+	}
+	switch c.base {
+	case 2, 8, 10, 16:
+	default:
+		return nil, true, fmt.Errorf("invalid base %d", c.base)
+	}
+	if !slices.Contains(outputFormats, c.format) {
+		return nil, true, fmt.Errorf("invalid format %q", c.format)
+	}
+	if c.config.Precision <= 0 || c.config.Precision > engine.MaxPrecision {
+		return nil, true, fmt.Errorf("invalid precision %d (1 to %d)", c.config.Precision, engine.MaxPrecision)
+	}
+	mode, ok := parseAngle(angle)
+	if !ok {
+		return nil, true, fmt.Errorf("invalid angle unit %q", angle)
+	}
+	c.config.Angle = mode
+	settings := loadSettings()
+	c.display = settings.format(settings.locale())
+	return c, true, nil
+}
+
+// redirected 判断标准输入是否来自管道或文件，终端和 /dev/null 等设备不算
+func redirected(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular()
+}
+
+// parseAngle 解析角度单位，不区分大小写
+func parseAngle(s string) (engine.AngleMode, bool) {
+	for _, m := range []engine.AngleMode{engine.Degrees, engine.Radians, engine.Gradians} {
+		if strings.EqualFold(s, m.String()) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// run 计算表达式并逐行输出结果，出错的表达式在标准错误中说明原因，其余表达式照常计算
+func (c *cli) run(stdin io.Reader, stdout, stderr io.Writer) int {
+	code := exitOK
+	eval := func(where, expr string) {
+		result, err := c.eval(expr)
+		if err != nil {
+			fmt.Fprintf(stderr, "gocalc: %s: %v\n", where, err)
+			code = exitError
+			return
+		}
+		fmt.Fprintln(stdout, result)
+	}
+	if len(c.exprs) > 0 {
+		for _, expr := range c.exprs {
+			eval(expr, expr)
+		}
+		return code
+	}
+
+	// 标准输入每行一个表达式，跳过空行和 # 开头的注释
+	scanner := bufio.NewScanner(stdin)
+	for line := 1; scanner.Scan(); line++ {
+		expr := strings.TrimSpace(scanner.Text())
+		if expr == "" || strings.HasPrefix(expr, "#") {
+			continue
+		}
+		eval(fmt.Sprintf("line %d", line), expr)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "gocalc: %v\n", err)
+		return exitError
+	}
+	return code
+}
+
+// eval 用与图形界面相同的引擎计算表达式，并按输出进制和格式转换为文字
+func (c *cli) eval(expr string) (string, error) {
+	d, err := c.config.Eval(expr)
+	if err != nil {
+		return "", err
+	}
+	if c.base != 10 {
+		if !d.IsInt() {
+			return "", errors.New("result is not an integer")
+		}
+		return strings.ToUpper(d.BigInt().Text(c.base)), nil
+	}
+	switch c.format {
+	case "grouped":
+		return c.display.Full(d), nil
+	case "display":
+		return c.display.Decimal(d), nil
+	case "sci":
+		return d.Scientific(), nil
+	}
+	return d.String(), nil
+}
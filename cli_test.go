@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCLI 与 main 一样解析参数并运行命令行模式，input 不为空时作为重定向的标准输入
+func runCLI(t *testing.T, args []string, input string) (stdout, stderr string, code int) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+
+	var out, errOut bytes.Buffer
+	cmd, ok, err := parseCLI(args, stdin, &errOut)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return out.String(), errOut.String(), exitOK
+	case err != nil:
+		return out.String(), errOut.String() + err.Error(), exitUsage
+	case !ok:
+		t.Fatalf("parseCLI(%q) would open the window", args)
+	}
+	code = cmd.run(strings.NewReader(input), &out, &errOut)
+	return out.String(), errOut.String(), code
+}
+
+func TestCLI(t *testing.T) {
+	tests := []struct {
+		args   []string
+		input  string
+		stdout string
+		code   int
+	}{
+		{[]string{"-e", "1 + 2"}, "", "3\n", exitOK},
+		{[]string{"-e", "0.1 + 0.2", "-e", "2^10"}, "", "0.3\n1024\n", exitOK},
+		{[]string{"-e", "1/3", "-precision", "5"}, "", "0.33333\n", exitOK},
+		{[]string{"-e", "sin(90)"}, "", "1\n", exitOK},
+		{[]string{"-e", "sin(100)", "-angle", "grad"}, "", "1\n", exitOK},
+		// 输出进制和格式
+		{[]string{"-e", "255", "-base", "16"}, "", "FF\n", exitOK},
+		{[]string{"-e", "5", "-base", "2"}, "", "101\n", exitOK},
+		{[]string{"-e", "-8", "-base", "8"}, "", "-10\n", exitOK},
+		{[]string{"-e", "1234567.5", "-format", "grouped"}, "", "1,234,567.5\n", exitOK},
+		{[]string{"-e", "2^70", "-format", "display"}, "", "1.180591620717411e+21\n", exitOK},
+		{[]string{"-e", "1234.5", "-format", "sci"}, "", "1.2345e+3\n", exitOK},
+		{[]string{"-e", "1e3", "-e", "2e-1"}, "", "1000\n0.2\n", exitOK},
+		// 标准输入每行一个表达式，跳过空行和注释
+		{nil, "1 + 1\n\n# comment\n2 × 3\n", "2\n6\n", exitOK},
+		{[]string{"-format", "sci"}, "100\n", "1e+2\n", exitOK},
+		// 出错的表达式不输出，其余照常计算
+		{[]string{"-e", "1/0", "-e", "2"}, "", "2\n", exitError},
+		{nil, "1 +\n4\n", "4\n", exitError},
+		{[]string{"-e", "1.5", "-base", "16"}, "", "", exitError},
+		// 参数错误
+		{[]string{"-base", "3", "-e", "1"}, "", "", exitUsage},
+		{[]string{"-format", "fancy", "-e", "1"}, "", "", exitUsage},
+		{[]string{"-precision", "0", "-e", "1"}, "", "", exitUsage},
+		{[]string{"-precision", "1001", "-e", "1"}, "", "", exitUsage},
+		{[]string{"-angle", "turn", "-e", "1"}, "", "", exitUsage},
+		{[]string{"1 + 2"}, "", "", exitUsage},
+		{[]string{"-nope"}, "", "", exitUsage},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runCLI(t, tt.args, tt.input)
+		if stdout != tt.stdout || code != tt.code {
+			t.Errorf("gocalc %q with input %q: got %q, exit %d (stderr %q); want %q, exit %d", tt.args, tt.input, stdout, code, stderr, tt.stdout, tt.code)
+		}
+	}
+}
+
+func TestCLIErrorMessages(t *testing.T) {
+	_, stderr, _ := runCLI(t, nil, "1 + 1\n2 ÷ 0\n")
+	if want := "gocalc: line 2: division by zero\n"; stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+	_, stderr, _ = runCLI(t, []string{"-e", "sqrt(-1)"}, "")
+	if !strings.HasPrefix(stderr, "gocalc: sqrt(-1): ") {
+		t.Errorf("stderr = %q, want the expression as the location", stderr)
+	}
+}
+
+// 没有参数且标准输入不是重定向时打开窗口
+func TestCLIOpensWindow(t *testing.T) {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	if _, ok, err := parseCLI(nil, devNull, &bytes.Buffer{}); ok || err != nil {
+		t.Errorf("parseCLI with no arguments = %v, %v; want the window", ok, err)
+	}
+}
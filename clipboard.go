@@ -0,0 +1,162 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"time"
+
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// noticeDuration 提示信息的显示时长
+const noticeDuration = 2 * time.Second
+
+// contextMenuItems 显示区右键菜单的菜单项
+var contextMenuItems = [...]string{"Copy", "Copy with separators", "Copy expression", "Copy as Chinese amount", "Paste"}
+
+// contextMenu 显示区的右键菜单
+type contextMenu struct {
+	open  bool
+	pos   image.Point // 菜单左上角在显示区中的位置
+	items [len(contextMenuItems)]widget.Clickable
+}
+
+// withContextMenu 为显示区添加右键菜单
+func (c *Calculator) withContextMenu(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: &c.menu, Kinds: pointer.Press})
+		if !ok {
+			break
+		}
+		if e, ok := ev.(pointer.Event); ok {
+			c.menu.open = e.Buttons.Contain(pointer.ButtonSecondary)
+			c.menu.pos = e.Position.Round()
+			c.invalidate()
+		}
+	}
+	for i := range c.menu.items {
+		if c.menu.items[i].Clicked(gtx) {
+			c.menu.open = false
+			c.contextMenuAction(gtx, contextMenuItems[i])
+		}
+	}
+
+	dims := w(gtx)
+	area := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
+	event.Op(gtx.Ops, &c.menu)
+	area.Pop()
+
+	if c.menu.open {
+		// 菜单绘制在所有内容之上
+		macro := op.Record(gtx.Ops)
+		c.layoutContextMenu(gtx, dims.Size)
+		op.Defer(gtx.Ops, macro.Stop())
+	}
+	return dims
+}
+
+// layoutContextMenu 绘制右键菜单，菜单不超出显示区的右边和下边
+func (c *Calculator) layoutContextMenu(gtx layout.Context, bounds image.Point) {
+	m := op.Record(gtx.Ops)
+	gtx.Constraints = layout.Constraints{Max: bounds}
+	dims := layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			rect := clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, gtx.Dp(unit.Dp(6)))
+			paint.FillShape(gtx.Ops, c.palette.Surface, rect.Op(gtx.Ops))
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			children := make([]layout.FlexChild, len(contextMenuItems))
+			for i, item := range contextMenuItems {
+				children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return c.menu.items[i].Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						label := material.Body2(c.theme, c.tr(item))
+						label.Color = c.palette.Text
+						label.TextSize = unit.Sp(14)
+						return layout.Inset{
+							Top: unit.Dp(8), Bottom: unit.Dp(8), Left: unit.Dp(12), Right: unit.Dp(12),
+						}.Layout(gtx, label.Layout)
+					})
+				})
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		}),
+	)
+	call := m.Stop()
+
+	pos := c.menu.pos
+	pos.X = max(min(pos.X, bounds.X-dims.Size.X), 0)
+	pos.Y = max(min(pos.Y, bounds.Y-dims.Size.Y), 0)
+	defer op.Offset(pos).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+// contextMenuAction 执行菜单项或快捷键对应的剪贴板操作
+func (c *Calculator) contextMenuAction(gtx layout.Context, item string) {
+	switch item {
+	case "Copy":
+		c.copyToClipboard(gtx, c.engine.CopyText(false))
+	case "Copy with separators":
+		c.copyToClipboard(gtx, c.engine.CopyText(true))
+	case "Copy expression":
+		c.copyToClipboard(gtx, strings.TrimSuffix(c.state.Expression, " ="))
+	case "Copy as Chinese amount":
+		amount, err := c.engine.ChineseAmount()
+		if err != nil {
+			c.showNotice(gtx, c.tr("Cannot convert: %v", err), c.palette.Danger)
+			break
+		}
+		c.copyToClipboard(gtx, amount)
+		// 提示中显示转换结果，方便核对
+		c.showNotice(gtx, c.tr("Copied: %s", amount), c.palette.Accent)
+	case "Paste":
+		gtx.Execute(clipboard.ReadCmd{Tag: c})
+	}
+	c.invalidate()
+}
+
+// copyToClipboard 把文字写入剪贴板
+func (c *Calculator) copyToClipboard(gtx layout.Context, s string) {
+	if s == "" {
+		return
+	}
+	gtx.Execute(clipboard.WriteCmd{Type: "application/text", Data: io.NopCloser(strings.NewReader(s))})
+	c.showNotice(gtx, c.tr("Copied"), c.palette.Accent)
+}
+
+// paste 载入剪贴板中的数字或表达式，无法解析时显示提示且不改变当前输入
+func (c *Calculator) paste(gtx layout.Context, text string) {
+	state, err := c.engine.Paste(text)
+	c.state = state
+	if err != nil {
+		c.showNotice(gtx, c.tr("Cannot paste: %v", err), c.palette.Danger)
+	}
+	c.invalidate()
+}
+
+// showNotice 在表达式行短暂显示提示信息
+func (c *Calculator) showNotice(gtx layout.Context, text string, color color.NRGBA) {
+	c.notice = text
+	c.noticeColor = color
+	c.noticeUntil = gtx.Now.Add(noticeDuration)
+}
+
+// currentNotice 返回仍在显示时间内的提示信息和颜色
+func (c *Calculator) currentNotice(gtx layout.Context) (string, color.NRGBA) {
+	if c.notice == "" || !gtx.Now.Before(c.noticeUntil) {
+		return "", color.NRGBA{}
+	}
+	gtx.Execute(op.InvalidateCmd{At: c.noticeUntil})
+	return c.notice, c.noticeColor
+}
@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"math/big"
+	"strings"
+)
+
+// ErrAmountTooLarge 金额超出大写金额能表示的范围（一万万亿元）
+var ErrAmountTooLarge = &Error{KindOverflow, "amount too large"}
+
+// upperDigits 大写数字
+var upperDigits = []rune("零壹贰叁肆伍陆柒捌玖")
+
+// upperUnits 一节 4 位中各位的单位
+var upperUnits = []string{"", "拾", "佰", "仟"}
+
+// maxCents 大写金额能表示的最大金额（以分计）加一
+var maxCents = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// ChineseAmount 把金额转换为中文大写金额，如 12345.67 为"壹万贰仟叁佰肆拾伍元陆角柒分"。
+// 金额四舍五入到分；到元或角为止时以"整"结尾，负数前加"负"
+func ChineseAmount(d Decimal) (string, error) {
+	if d.exp < -2 {
+		d = d.roundAt(-2)
+	}
+	cents := d.Shift(2).BigInt()
+	negative := cents.Sign() < 0
+	cents.Abs(cents)
+	if cents.Cmp(maxCents) >= 0 {
+		return "", ErrAmountTooLarge
+	}
+
+	digits := cents.String()
+	if len(digits) < 3 {
+		digits = strings.Repeat("0", 3-len(digits)) + digits
+	}
+	yuan, jiao, fen := digits[:len(digits)-2], digits[len(digits)-2], digits[len(digits)-1]
+
+	var b strings.Builder
+	if negative {
+		b.WriteString("负")
+	}
+	if yuan != "0" {
+		b.WriteString(upperInt(yuan))
+		b.WriteString("元")
+	}
+	switch {
+	case jiao == '0' && fen == '0':
+		if yuan == "0" {
+			b.WriteString("零元")
+		}
+		b.WriteString("整")
+	case fen == '0':
+		b.WriteRune(upperDigits[jiao-'0'])
+		b.WriteString("角整")
+	default:
+		if jiao != '0' {
+			b.WriteRune(upperDigits[jiao-'0'])
+			b.WriteString("角")
+		} else if yuan != "0" {
+			b.WriteString("零")
+		}
+		b.WriteRune(upperDigits[fen-'0'])
+		b.WriteString("分")
+	}
+	return b.String(), nil
+}
+
+// upperInt 把不超过 16 位的整数转换为大写数字，每 4 位一节，节单位为万、亿、万亿；
+// 中间连续的 0 只写一个"零"，末尾的 0 不写
+func upperInt(digits string) string {
+	var b strings.Builder
+	zero := false    // 有尚未写出的 0
+	nonZero := false // 当前节中有非零数字
+	for i, r := range digits {
+		pos := len(digits) - 1 - i
+		if r == '0' {
+			zero = true
+		} else {
+			if zero && b.Len() > 0 {
+				b.WriteString("零")
+			}
+			zero = false
+			nonZero = true
+			b.WriteRune(upperDigits[r-'0'])
+			b.WriteString(upperUnits[pos%4])
+		}
+		if pos%4 != 0 || pos == 0 {
+			continue
+		}
+		// 一节结束，节中有非零数字时写出节单位
+		if nonZero {
+			switch section := pos / 4; {
+			case section == 1:
+				b.WriteString("万")
+			case section == 2:
+				b.WriteString("亿")
+			case strings.Trim(digits[i+1:i+5], "0") != "":
+				// 亿这一节不为 0 时由它写出"亿"，如 12万3456亿
+				b.WriteString("万")
+			default:
+				b.WriteString("万亿")
+			}
+		}
+		nonZero = false
+	}
+	return b.String()
+}
+
+// myriadGroups 整数部分每 4 位一组并加上万、亿单位，如 123456789 为 1亿2345万6789；
+// 没有小数部分时省略末尾全为 0 的组，如 1亿。超过 16 位时返回 false
+func myriadGroups(s string, fraction bool) (string, bool) {
+	groups := (len(s) + 3) / 4
+	if groups > 4 {
+		return "", false
+	}
+	low := 0 // 写出的最低一组
+	if !fraction {
+		for low < groups-1 && strings.Trim(s[len(s)-4*(low+1):], "0") == "" {
+			low++
+		}
+	}
+	var b strings.Builder
+	for g := groups - 1; g >= low; g-- {
+		start := max(len(s)-4*(g+1), 0)
+		b.WriteString(s[start : len(s)-4*g])
+		switch {
+		case g == 1:
+			b.WriteString("万")
+		case g == 2:
+			b.WriteString("亿")
+		case g == 3 && low == 3:
+			b.WriteString("万亿")
+		case g == 3:
+			b.WriteString("万")
+		}
+	}
+	return b.String(), true
+}
+
+// expandMyriad 把文字中带万、亿单位的数字展开为普通数字，如 "1亿2345万6789" 为 "123456789"、
+// "1.5万" 为 "15000"，其他内容保持不变
+func expandMyriad(text string) string {
+	if !strings.ContainsAny(text, "万亿") {
+		return text
+	}
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && strings.ContainsRune("0123456789.万亿", runes[j]) {
+			j++
+		}
+		if j == i {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		run := string(runes[i:j])
+		if value, ok := parseMyriad(run); ok {
+			b.WriteString(value.String())
+		} else {
+			b.WriteString(run)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// parseMyriad 解析带万、亿单位的数字：万乘以一万加到当前节，亿把之前的全部乘以一亿
+func parseMyriad(s string) (Decimal, bool) {
+	if !strings.ContainsAny(s, "万亿") {
+		return Decimal{}, false
+	}
+	var result, section Decimal
+	num := ""
+	unit := func(n Decimal) (Decimal, bool) {
+		if num == "" {
+			return NewDecimal(0), true
+		}
+		v, err := ParseDecimal(num)
+		num = ""
+		return v.Mul(n), err == nil
+	}
+	for _, r := range s {
+		var ok bool
+		var v Decimal
+		switch r {
+		case '万':
+			if v, ok = unit(NewDecimal(10000)); !ok {
+				return Decimal{}, false
+			}
+			section = section.Add(v)
+		case '亿':
+			if v, ok = unit(NewDecimal(1)); !ok {
+				return Decimal{}, false
+			}
+			result = result.Add(section).Add(v).Mul(NewDecimal(100000000))
+			section = Decimal{}
+		default:
+			num += string(r)
+		}
+	}
+	rest, ok := unit(NewDecimal(1))
+	if !ok {
+		return Decimal{}, false
+	}
+	return result.Add(section).Add(rest), true
+}
+
+// ChineseAmount 返回当前值的中文大写金额，当前处于错误状态时返回该错误
+func (e *Engine) ChineseAmount() (string, error) {
+	value, ok := e.memoryValue()
+	if !ok {
+		return "", e.err
+	}
+	return ChineseAmount(value)
+}
@@ -0,0 +1,89 @@
+package engine
+
+import "testing"
+
+func TestChineseAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   string
+	}{
+		{"12345.67", "壹万贰仟叁佰肆拾伍元陆角柒分"},
+		{"10.05", "壹拾元零伍分"},
+		{"0.05", "伍分"},
+		{"0.5", "伍角整"},
+		{"1.234", "壹元贰角叁分"},
+		{"0.005", "壹分"},
+		{"10.5", "壹拾元伍角整"},
+		// 中间连续的零只写一个，节末尾的零不写
+		{"100000001", "壹亿零壹元整"},
+		{"1000010", "壹佰万零壹拾元整"},
+		{"20001", "贰万零壹元整"},
+		{"1010", "壹仟零壹拾元整"},
+		{"100000000.1", "壹亿元壹角整"},
+		// 整数金额以"整"结尾
+		{"100", "壹佰元整"},
+		{"0", "零元整"},
+		// 负数
+		{"-12.5", "负壹拾贰元伍角整"},
+		{"-0.05", "负伍分"},
+		{"9999999999999999.99", "玖仟玖佰玖拾玖万玖仟玖佰玖拾玖亿玖仟玖佰玖拾玖万玖仟玖佰玖拾玖元玖角玖分"},
+	}
+	for _, tt := range tests {
+		got, err := ChineseAmount(mustParse(t, tt.amount))
+		if err != nil {
+			t.Errorf("ChineseAmount(%s) error: %v", tt.amount, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ChineseAmount(%s) = %s, want %s", tt.amount, got, tt.want)
+		}
+	}
+
+	for _, amount := range []string{"10000000000000000", "-10000000000000000", "1e20"} {
+		if _, err := ChineseAmount(mustParse(t, amount)); err != ErrAmountTooLarge {
+			t.Errorf("ChineseAmount(%s) error %v, want ErrAmountTooLarge", amount, err)
+		}
+	}
+}
+
+func TestFormatMyriad(t *testing.T) {
+	f := DefaultFormat
+	f.Myriad = true
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"1234", "1234"},
+		{"10000", "1万"},
+		{"12345.6", "1万2345.6"},
+		{"123456789", "1亿2345万6789"},
+		{"100000000", "1亿"},
+		{"1000000000000", "1万亿"},
+		{"-20000500", "-2000万0500"},
+		// 超过 16 位时使用科学记数法
+		{"123456789012345678", "1.234567890123457e+17"},
+	}
+	for _, tt := range tests {
+		if got := f.Decimal(mustParse(t, tt.value)); got != tt.want {
+			t.Errorf("Decimal(%s) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExpandMyriad(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"1亿2345万6789", "123456789"},
+		{"1.5万", "15000"},
+		{"2万亿", "2000000000000"},
+		{"3万 + 1亿", "30000 + 100000000"},
+		{"1234", "1234"},
+	}
+	for _, tt := range tests {
+		if got := expandMyriad(tt.text); got != tt.want {
+			t.Errorf("expandMyriad(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrEmptyPaste 粘贴的内容中没有数字或表达式
+var ErrEmptyPaste = errors.New("nothing to paste")
+
+// maxPasteDigits 粘贴的单个数字展开为普通记数法后最多的字符数
+const maxPasteDigits = 100
+
+// currencySymbols 粘贴时忽略的货币符号
+const currencySymbols = "$¥￥€£₩₹"
+
+// CopyText 返回要复制的当前值：grouped 为真时与显示一致（带分隔符），
+// 否则为不带千位分隔符的完整精度数字，两者都使用显示格式的小数点。出错时返回空字符串
+func (e *Engine) CopyText(grouped bool) string {
+	if e.evaluated && e.err != nil {
+		return ""
+	}
+	if grouped {
+		return e.display
+	}
+	value := e.currentValue()
+	if e.mode == ModeProgrammer {
+		return e.entryText(value.String())
+	}
+	return e.format.localize(value.String())
+}
+
+// Paste 把粘贴的文字载入计算器：单个数字作为正在输入的数字，
+// 完整表达式替换当前表达式。千位分隔符、货币符号和多余空白会被去掉，带万、亿单位的数字会被展开，
+// 内容无法解析时返回错误且状态不变
+func (e *Engine) Paste(text string) (State, error) {
+	var err error
+	e.track(func() { err = e.paste(text) })
+	return e.State(), err
+}
+
+// InsertValue 把常数按键的值作为正在输入的数字，与语言区域无关。
+// 数字展开后过长时返回 ErrOverflow 且状态不变
+func (e *Engine) InsertValue(d Decimal) (State, error) {
+	if len(d.String()) > maxPasteDigits {
+		return e.State(), ErrOverflow
+	}
+	e.track(func() { e.recall(d) })
+	return e.State(), nil
+}
+
+func (e *Engine) paste(text string) error {
+	text = cleanPaste(expandMyriad(e.format.delocalize(text)))
+	if text == "" {
+		return ErrEmptyPaste
+	}
+
+	// 数字中间的空格也可能是千位分隔符
+	compact := strings.ReplaceAll(text, " ", "")
+	if e.base() != 10 && validDigits(compact, e.base()) {
+		entry := strings.TrimLeft(strings.ToUpper(compact), "0")
+		if entry == "" {
+			entry = "0"
+		}
+		if !e.fitsWord(entry) {
+			return ErrOverflow
+		}
+		e.recall(Decimal{})
+		e.setEntry(entry)
+		return nil
+	}
+	value, err := parseNumber(compact)
+	switch {
+	case errors.Is(err, ErrOverflow):
+		return err
+	case err == nil:
+		// 数字作为正在输入的数字完整展开，1e99999 这样的数字会变成极长的文字
+		if len(value.String()) > maxPasteDigits {
+			return ErrOverflow
+		}
+		// 与键入一样，十进制数超出字长时不能输入
+		if e.mode == ModeProgrammer && e.base() == 10 && !e.fitsWord(value.BigInt().String()) {
+			return ErrOverflow
+		}
+		e.recall(value)
+		return nil
+	}
+
+	tokens, err := splitTokens(text)
+	if err != nil {
+		return err
+	}
+	if _, err := Parse(joinTokens(tokens)); err != nil {
+		return err
+	}
+	e.loadExpression(tokens)
+	return nil
+}
+
+// cleanPaste 去掉货币符号和末尾的等号，连续空白合并为一个空格；
+// 千位分隔符和小数点已按显示格式转换
+func cleanPaste(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimSpace(strings.TrimSuffix(text, "="))
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(currencySymbols, r):
+			continue
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// validDigits 判断文字是否全部由指定进制的数字组成
+func validDigits(s string, base int) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range strings.ToUpper(s) {
+		if !ValidDigit(Key(string(r)), base) {
+			return false
+		}
+	}
+	return true
+}
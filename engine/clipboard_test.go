@@ -0,0 +1,152 @@
+package engine
+
+import "testing"
+
+func TestInsertValue(t *testing.T) {
+	german := DefaultFormat
+	german.Point, german.Group = ',', '.'
+	tests := []struct {
+		format  Format
+		value   string
+		display string
+	}{
+		{DefaultFormat, "9.80665", "9.80665"},
+		{german, "9.80665", "9,80665"},
+		{german, "6.6743e-11", "0,000000000066743"},
+		{german, "299792458", "299.792.458"},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.SetFormat(tt.format)
+		s, err := e.InsertValue(mustParse(t, tt.value))
+		if err != nil {
+			t.Errorf("InsertValue(%s) error: %v", tt.value, err)
+			continue
+		}
+		if s.Display != tt.display {
+			t.Errorf("InsertValue(%s) shows %q, want %q", tt.value, s.Display, tt.display)
+		}
+		// 常数与正在输入的数字一样参与计算
+		if s = pressAll(e, keys("× 2 =")); s.Err != nil {
+			t.Errorf("InsertValue(%s) × 2 = error: %v", tt.value, s.Err)
+		}
+	}
+
+	if _, err := New().InsertValue(mustParse(t, "1e999")); err != ErrOverflow {
+		t.Errorf("InsertValue(1e999) error %v, want ErrOverflow", err)
+	}
+}
+
+func mustParse(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := ParseDecimal(s)
+	if err != nil {
+		t.Fatalf("ParseDecimal(%q): %v", s, err)
+	}
+	return d
+}
+
+func TestPaste(t *testing.T) {
+	german := DefaultFormat
+	german.Point, german.Group = ',', '.'
+	tests := []struct {
+		format     Format
+		text       string
+		display    string
+		expression string
+	}{
+		// 千位分隔符、货币符号和多余空白被去掉
+		{DefaultFormat, "1,234.5", "1,234.5", "1,234.5"},
+		{DefaultFormat, "$1,234.50", "1,234.5", "1,234.5"},
+		{DefaultFormat, "€12", "12", "12"},
+		{DefaultFormat, "¥ 1 234", "1,234", "1,234"},
+		{DefaultFormat, "  -42  ", "-42", "-42"},
+		// 带万、亿单位的数字展开
+		{DefaultFormat, "1亿2345万", "123,450,000", "123,450,000"},
+		{DefaultFormat, "1.5万", "15,000", "15,000"},
+		// 表达式替换当前表达式，末尾的等号被去掉
+		{DefaultFormat, "12 + 3 =", "3", "12 + 3"},
+		{DefaultFormat, "(1+2)×3", "3", "(1 + 2) × 3"},
+		{DefaultFormat, "1e+22 × 1,000", "1,000", "1e+22 × 1,000"},
+		// 按显示格式的小数点和千位分隔符解释
+		{german, "1.234,5", "1.234,5", "1.234,5"},
+		{german, "1,5", "1,5", "1,5"},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.SetFormat(tt.format)
+		s, err := e.Paste(tt.text)
+		if err != nil {
+			t.Errorf("Paste(%q) error: %v", tt.text, err)
+			continue
+		}
+		if s.Display != tt.display || s.Expression != tt.expression {
+			t.Errorf("Paste(%q): got %q, %q; want %q, %q", tt.text, s.Display, s.Expression, tt.display, tt.expression)
+		}
+	}
+}
+
+func TestPasteProgrammer(t *testing.T) {
+	tests := []struct {
+		base       Key
+		text       string
+		display    string
+		expression string
+	}{
+		{KeyHex, "FF", "FF", "0xFF"},
+		{KeyHex, "ff", "FF", "0xFF"},
+		{KeyHex, "0xFF", "FF", "0xFF"},
+		{KeyHex, "1F 2A", "1F2A", "0x1F2A"},
+		{KeyBin, "1010", "1010", "0b1010"},
+		{KeyDec, "0xFF", "255", "255"},
+		{KeyDec, "-9223372036854775808", "-9,223,372,036,854,775,808", "-9,223,372,036,854,775,808"},
+		{KeyDec, "0b11 + 1", "1", "0b11 + 1"},
+		// 小数截断为整数
+		{KeyDec, "1.5", "1", "1"},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.SetMode(ModeProgrammer)
+		e.Press(tt.base)
+		s, err := e.Paste(tt.text)
+		if err != nil {
+			t.Errorf("%s Paste(%q) error: %v", tt.base, tt.text, err)
+			continue
+		}
+		if s.Display != tt.display || s.Expression != tt.expression {
+			t.Errorf("%s Paste(%q): got %q, %q; want %q, %q", tt.base, tt.text, s.Display, s.Expression, tt.display, tt.expression)
+		}
+	}
+}
+
+func TestPasteErrors(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		text string
+		err  error
+		kind ErrorKind
+	}{
+		{ModeBasic, "", ErrEmptyPaste, KindOther},
+		{ModeBasic, " $ ", ErrEmptyPaste, KindOther},
+		{ModeBasic, "abc", nil, KindSyntax},
+		{ModeBasic, "1 +", nil, KindSyntax},
+		// 展开后过长的数字
+		{ModeBasic, "1e150", ErrOverflow, KindOverflow},
+		{ModeBasic, "1e999999999", ErrOverflow, KindOverflow},
+		{ModeProgrammer, "9223372036854775808", ErrOverflow, KindOverflow},
+		{ModeProgrammer, "-9223372036854775809", ErrOverflow, KindOverflow},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.SetMode(tt.mode)
+		before := pressAll(e, keys("7"))
+		s, err := e.Paste(tt.text)
+		if err == nil || tt.err != nil && err != tt.err || KindOf(err) != tt.kind {
+			t.Errorf("Paste(%q) error %v, want %v of kind %v", tt.text, err, tt.err, tt.kind)
+		}
+		// 出错时状态不变
+		if s != before {
+			t.Errorf("Paste(%q) changed the state to %q, %q", tt.text, s.Display, s.Expression)
+		}
+	}
+}
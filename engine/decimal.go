@@ -0,0 +1,346 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultPrecision 默认有效数字位数（与 IEEE 754 decimal128 相同）
+const DefaultPrecision = 34
+
+// MaxPrecision 有效数字位数的上限，精度过高时除法和函数的计算时间过长
+const MaxPrecision = 1000
+
+var bigTen = big.NewInt(10)
+
+// Decimal 任意精度十进制数，值为 coef × 10^exp
+//
+// Decimal 是不可变的值类型，零值表示 0。加、减、乘运算是精确的，
+// 只有除法等无法精确表示的运算需要按指定的有效数字位数舍入。
+type Decimal struct {
+	coef *big.Int // nil 表示 0
+	exp  int
+}
+
+// NewDecimal 由整数创建 Decimal
+func NewDecimal(n int64) Decimal {
+	return Decimal{coef: big.NewInt(n)}.normalize()
+}
+
+// MaxExponent 数值十进制指数的上限，绝对值超出时返回 ErrOverflow，
+// 避免 1e999999999 这样很短的输入展开为极大的整数
+const MaxExponent = 100000
+
+// ParseDecimal 解析十进制数字文字，支持 "12.5"、".5"、"-3"、"1.2e-7" 等形式，
+// 指数超出 ±MaxExponent 时返回 ErrOverflow
+func ParseDecimal(s string) (Decimal, error) {
+	text := s
+	exp := 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(text[i+1:])
+		if err != nil {
+			var numErr *strconv.NumError
+			if !errors.As(err, &numErr) || numErr.Err != strconv.ErrRange {
+				return Decimal{}, fmt.Errorf("invalid number %q", s)
+			}
+			e = MaxExponent + 1
+		}
+		exp = e
+		text = text[:i]
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(text, "-"):
+		negative = true
+		text = text[1:]
+	case strings.HasPrefix(text, "+"):
+		text = text[1:]
+	}
+
+	intPart, frac, _ := strings.Cut(text, ".")
+	digits := intPart + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid number %q", s)
+	}
+	if exp > MaxExponent || exp < -MaxExponent {
+		return Decimal{}, ErrOverflow
+	}
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if negative {
+		coef.Neg(coef)
+	}
+	d := Decimal{coef: coef, exp: exp - len(frac)}.normalize()
+	if err := d.checkRange(); err != nil {
+		return Decimal{}, err
+	}
+	return d, nil
+}
+
+// NewDecimalFromBigInt 由大整数创建 Decimal
+func NewDecimalFromBigInt(n *big.Int) Decimal {
+	return Decimal{coef: new(big.Int).Set(n)}.normalize()
+}
+
+// NewDecimalFromFloat 由 float64 创建 Decimal，使用能还原该浮点数的最短十进制表示。
+// NaN 返回 ErrDomain，无穷大返回 ErrOverflow
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	switch {
+	case math.IsNaN(f):
+		return Decimal{}, ErrDomain
+	case math.IsInf(f, 0):
+		return Decimal{}, ErrOverflow
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// normalize 去掉系数末尾的 0，保证同一个值只有一种表示
+func (d Decimal) normalize() Decimal {
+	if d.coef == nil || d.coef.Sign() == 0 {
+		return Decimal{}
+	}
+	coef := new(big.Int).Set(d.coef)
+	exp := d.exp
+	q, r := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(coef, bigTen, r)
+		if r.Sign() != 0 {
+			break
+		}
+		coef.Set(q)
+		exp++
+	}
+	return Decimal{coef: coef, exp: exp}
+}
+
+// align 把两个数的系数调整到相同的指数
+func align(x, y Decimal) (*big.Int, *big.Int, int) {
+	a, b := new(big.Int).Set(x.int()), new(big.Int).Set(y.int())
+	switch {
+	case x.exp > y.exp:
+		a.Mul(a, pow10(x.exp-y.exp))
+		return a, b, y.exp
+	case y.exp > x.exp:
+		b.Mul(b, pow10(y.exp-x.exp))
+		return a, b, x.exp
+	}
+	return a, b, x.exp
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// numDigits 返回整数的十进制位数（不含符号）
+func numDigits(n *big.Int) int {
+	if n.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(n).String())
+}
+
+// Add 返回 d + y
+func (d Decimal) Add(y Decimal) Decimal {
+	a, b, exp := align(d, y)
+	return Decimal{coef: a.Add(a, b), exp: exp}.normalize()
+}
+
+// Sub 返回 d - y
+func (d Decimal) Sub(y Decimal) Decimal {
+	a, b, exp := align(d, y)
+	return Decimal{coef: a.Sub(a, b), exp: exp}.normalize()
+}
+
+// Mul 返回 d × y
+func (d Decimal) Mul(y Decimal) Decimal {
+	coef := new(big.Int).Mul(d.int(), y.int())
+	return Decimal{coef: coef, exp: d.exp + y.exp}.normalize()
+}
+
+// checkedMul 返回 x × y，先由两数的量级估计积的指数，超出 ±MaxExponent 时不计算，返回 ErrOverflow
+func checkedMul(x, y Decimal) (Decimal, error) {
+	if x.IsZero() || y.IsZero() {
+		return Decimal{}, nil
+	}
+	if exp := x.magnitude() + y.magnitude(); exp > MaxExponent+1 || exp < -MaxExponent-1 {
+		return Decimal{}, ErrOverflow
+	}
+	d := x.Mul(y)
+	if err := d.checkRange(); err != nil {
+		return Decimal{}, err
+	}
+	return d, nil
+}
+
+// magnitude 返回科学记数法中的指数，即 d = m × 10^magnitude，1 ≤ |m| < 10
+func (d Decimal) magnitude() int {
+	_, pointPos := d.Digits()
+	return pointPos - 1
+}
+
+// checkRange 数值的指数超出 ±MaxExponent 时返回 ErrOverflow
+func (d Decimal) checkRange() error {
+	if d.IsZero() {
+		return nil
+	}
+	if exp := d.magnitude(); exp > MaxExponent || exp < -MaxExponent {
+		return ErrOverflow
+	}
+	return nil
+}
+
+// Quo 返回 d ÷ y，结果保留 prec 位有效数字。y 为 0 时返回 ErrDivideByZero
+func (d Decimal) Quo(y Decimal, prec int) (Decimal, error) {
+	if y.IsZero() {
+		return Decimal{}, ErrDivideByZero
+	}
+	if d.IsZero() {
+		return Decimal{}, nil
+	}
+	// 放大被除数，使商至少有 prec+1 位，多出的一位用于舍入
+	shift := prec + 1 + numDigits(y.coef) - numDigits(d.coef)
+	if shift < 0 {
+		shift = 0
+	}
+	num := new(big.Int).Mul(d.coef, pow10(shift))
+	q := new(big.Int).Quo(num, y.coef)
+	return Decimal{coef: q, exp: d.exp - y.exp - shift}.Round(prec), nil
+}
+
+// powInt 返回 d 的 n 次方，n 为非负整数
+func (d Decimal) powInt(n int64) Decimal {
+	coef := new(big.Int).Exp(d.int(), big.NewInt(n), nil)
+	return Decimal{coef: coef, exp: d.exp * int(n)}.normalize()
+}
+
+// Neg 返回 -d
+func (d Decimal) Neg() Decimal {
+	if d.IsZero() {
+		return d
+	}
+	return Decimal{coef: new(big.Int).Neg(d.coef), exp: d.exp}
+}
+
+// Abs 返回 |d|
+func (d Decimal) Abs() Decimal {
+	if d.Sign() < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Shift 返回 d × 10^n
+func (d Decimal) Shift(n int) Decimal {
+	if d.IsZero() {
+		return d
+	}
+	return Decimal{coef: d.coef, exp: d.exp + n}
+}
+
+// Round 按四舍五入保留 prec 位有效数字
+func (d Decimal) Round(prec int) Decimal {
+	if d.IsZero() || prec <= 0 {
+		return d.normalize()
+	}
+	drop := numDigits(d.coef) - prec
+	if drop <= 0 {
+		return d.normalize()
+	}
+	return d.roundAt(d.exp + drop)
+}
+
+// roundAt 四舍五入到 10^exp 位
+func (d Decimal) roundAt(exp int) Decimal {
+	div := pow10(exp - d.exp)
+	abs := new(big.Int).Abs(d.coef)
+	q, r := new(big.Int).QuoRem(abs, div, new(big.Int))
+	if r.Lsh(r, 1).Cmp(div) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if d.coef.Sign() < 0 {
+		q.Neg(q)
+	}
+	return Decimal{coef: q, exp: exp}.normalize()
+}
+
+// Sign 返回 -1、0 或 1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero 判断是否为 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// IsInt 判断是否为整数
+func (d Decimal) IsInt() bool {
+	return d.IsZero() || d.exp >= 0
+}
+
+// Cmp 比较 d 和 y，返回 -1、0 或 1
+func (d Decimal) Cmp(y Decimal) int {
+	a, b, _ := align(d, y)
+	return a.Cmp(b)
+}
+
+// BigInt 返回 d 的整数部分
+func (d Decimal) BigInt() *big.Int {
+	if d.exp >= 0 {
+		return new(big.Int).Mul(d.int(), pow10(d.exp))
+	}
+	return new(big.Int).Quo(d.int(), pow10(-d.exp))
+}
+
+// Digits 返回系数的十进制数字（不含符号）以及小数点前的位数，
+// 例如 12.5 返回 ("125", 2)，0.003 返回 ("3", -2)
+func (d Decimal) Digits() (digits string, pointPos int) {
+	if d.IsZero() {
+		return "0", 1
+	}
+	digits = new(big.Int).Abs(d.coef).String()
+	return digits, len(digits) + d.exp
+}
+
+// Scientific 返回 "1.234e+5" 形式的文字
+func (d Decimal) Scientific() string {
+	digits, pointPos := d.Digits()
+	s := digits[:1]
+	if len(digits) > 1 {
+		s += "." + digits[1:]
+	}
+	s += fmt.Sprintf("e%+d", pointPos-1)
+	if d.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// String 返回不带指数的完整十进制文字，如 "-1234.5"、"0.001"
+func (d Decimal) String() string {
+	digits, pointPos := d.Digits()
+	var s string
+	switch {
+	case pointPos <= 0:
+		s = "0." + strings.Repeat("0", -pointPos) + digits
+	case pointPos >= len(digits):
+		s = digits + strings.Repeat("0", pointPos-len(digits))
+	default:
+		s = digits[:pointPos] + "." + digits[pointPos:]
+	}
+	if d.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
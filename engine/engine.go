@@ -0,0 +1,799 @@
+// Package engine 计算器核心状态机，不依赖任何 GUI，可单独复用和测试
+package engine
+
+import (
+	"slices"
+	"strings"
+)
+
+// Key 计算器按键
+type Key string
+
+// 按键定义
+const (
+	Key0 Key = "0"
+	Key1 Key = "1"
+	Key2 Key = "2"
+	Key3 Key = "3"
+	Key4 Key = "4"
+	Key5 Key = "5"
+	Key6 Key = "6"
+	Key7 Key = "7"
+	Key8 Key = "8"
+	Key9 Key = "9"
+
+	KeyDot        Key = "."
+	KeyAdd        Key = "+"
+	KeySub        Key = "-"
+	KeyMul        Key = "×"
+	KeyDiv        Key = "÷"
+	KeyEquals     Key = "="
+	KeyPercent    Key = "%"
+	KeyDeltaPct   Key = "Δ%" // 变化百分比
+	KeyMarkup     Key = "MU" // 按加价率计算售价
+	KeyMargin     Key = "MG" // 按毛利率计算售价
+	KeyNegate     Key = "±"
+	KeyBackspace  Key = "⌫"
+	KeyClear      Key = "AC"
+	KeyClearEntry Key = "CE"
+
+	KeyLParen Key = "("
+	KeyRParen Key = ")"
+
+	// 科学计算
+	KeySin        Key = "sin"
+	KeyCos        Key = "cos"
+	KeyTan        Key = "tan"
+	KeyAsin       Key = "sin⁻¹"
+	KeyAcos       Key = "cos⁻¹"
+	KeyAtan       Key = "tan⁻¹"
+	KeySinh       Key = "sinh"
+	KeyCosh       Key = "cosh"
+	KeyTanh       Key = "tanh"
+	KeyAsinh      Key = "sinh⁻¹"
+	KeyAcosh      Key = "cosh⁻¹"
+	KeyAtanh      Key = "tanh⁻¹"
+	KeyLn         Key = "ln"
+	KeyLog        Key = "log"
+	KeyLog2       Key = "log₂"
+	KeySquare     Key = "x²"
+	KeyPower      Key = "xʸ"
+	KeySqrt       Key = "√"
+	KeyRoot       Key = "ʸ√x"
+	KeyReciprocal Key = "1/x"
+	KeyFactorial  Key = "n!"
+	KeyAbs        Key = "|x|"
+	KeyPi         Key = "π"
+	KeyE          Key = "e"
+	KeyAngle      Key = "DRG" // 切换角度单位
+
+	// 程序员模式
+	KeyA    Key = "A"
+	KeyB    Key = "B"
+	KeyC    Key = "C"
+	KeyD    Key = "D"
+	KeyE16  Key = "E" // 十六进制数字 E，与常数 e 区分
+	KeyF    Key = "F"
+	KeyHex  Key = "HEX"
+	KeyDec  Key = "DEC"
+	KeyOct  Key = "OCT"
+	KeyBin  Key = "BIN"
+	KeyAnd  Key = "AND"
+	KeyOr   Key = "OR"
+	KeyXor  Key = "XOR"
+	KeyNot  Key = "NOT"
+	KeyNand Key = "NAND"
+	KeyNor  Key = "NOR"
+	KeyLsh  Key = "<<"
+	KeyRsh  Key = ">>"
+	KeyRol  Key = "ROL"
+	KeyRor  Key = "ROR"
+	KeyMod  Key = "MOD"
+	KeyWord Key = "Word" // 循环切换字长
+	KeySign Key = "Sign" // 切换有符号/无符号
+
+	// 存储器
+	KeyMC   Key = "MC" // 清除全部存储
+	KeyMR   Key = "MR" // 读取最近的存储
+	KeyMAdd Key = "M+" // 当前值加到最近的存储
+	KeyMSub Key = "M−" // 从最近的存储中减去当前值
+	KeyMS   Key = "MS" // 把当前值存入新的存储
+
+	// 撤销和重做
+	KeyUndo Key = "Undo"
+	KeyRedo Key = "Redo"
+)
+
+// functionKeys 函数按键对应表达式中的函数名
+var functionKeys = map[Key]string{
+	KeySin:   "sin",
+	KeyCos:   "cos",
+	KeyTan:   "tan",
+	KeyAsin:  "sin⁻¹",
+	KeyAcos:  "cos⁻¹",
+	KeyAtan:  "tan⁻¹",
+	KeySinh:  "sinh",
+	KeyCosh:  "cosh",
+	KeyTanh:  "tanh",
+	KeyAsinh: "sinh⁻¹",
+	KeyAcosh: "cosh⁻¹",
+	KeyAtanh: "tanh⁻¹",
+	KeyLn:    "ln",
+	KeyLog:   "log",
+	KeyLog2:  "log₂",
+	KeySqrt:  "√",
+	KeyAbs:   "abs",
+}
+
+// binaryKeys 二元运算按键对应的运算符
+var binaryKeys = map[Key]string{
+	KeyAdd:      "+",
+	KeySub:      "-",
+	KeyMul:      "×",
+	KeyDiv:      "÷",
+	KeyPower:    "^",
+	KeyRoot:     "ʸ√",
+	KeyAnd:      "AND",
+	KeyOr:       "OR",
+	KeyXor:      "XOR",
+	KeyNand:     "NAND",
+	KeyNor:      "NOR",
+	KeyLsh:      "<<",
+	KeyRsh:      ">>",
+	KeyRol:      "ROL",
+	KeyRor:      "ROR",
+	KeyMod:      "MOD",
+	KeyDeltaPct: "Δ%",
+	KeyMarkup:   "MU",
+	KeyMargin:   "MG",
+}
+
+// baseKeys 进制切换按键
+var baseKeys = map[Key]int{
+	KeyHex: 16,
+	KeyDec: 10,
+	KeyOct: 8,
+	KeyBin: 2,
+}
+
+// postfixKeys 后缀运算按键对应的运算符
+var postfixKeys = map[Key]string{
+	KeyPercent:   "%",
+	KeySquare:    "²",
+	KeyFactorial: "!",
+}
+
+// errorText 出错时显示的文字，界面可根据 State.Err 显示翻译后的文字
+const errorText = "Error"
+
+// State 计算器某一时刻的只读快照
+type State struct {
+	Display    string    // 当前显示的数字
+	Expression string    // 正在输入的完整表达式，计算后为 "表达式 ="
+	Operator   string    // 等待计算的运算符
+	Angle      AngleMode // 三角函数的角度单位
+	Mode       Mode      // 计算器模式
+	Base       int       // 程序员模式的输入和显示进制
+	Word       WordSize  // 程序员模式的字长
+	Bits       uint64    // 程序员模式下当前值的二进制补码位模式
+	Memory     bool      // 存储器中是否有值
+	CanUndo    bool      // 是否有可以撤销的操作
+	CanRedo    bool      // 是否有可以重做的操作
+	Err        error     // 最近一次计算的错误
+}
+
+// Engine 计算器状态机
+//
+// 按键被累积为表达式词法单元，按下等号时整体解析并按优先级计算。
+type Engine struct {
+	tokens     []string // 已完成输入的词法单元：数字、运算符、括号、百分号
+	entry      string   // 正在输入的数字，不含千位分隔符
+	display    string
+	expression string
+	ans        Decimal // 上一次计算结果
+	evaluated  bool    // 刚按过等号，再输入数字将开始新的表达式
+	err        error
+	repeat     []string // 再次按等号时重复的运算符和右操作数，如 5 + 3 = 之后为 + 3
+	config     Config
+	format     Format
+	mode       Mode
+	radix      int // 程序员模式的进制
+	history    []HistoryEntry
+	onHistory  func(HistoryEntry)
+	memory     []MemorySlot
+	onMemory   func([]MemorySlot)
+	replace    bool // 下一个数字替换正在输入的数字，如存储或读取存储之后
+	undo       []snapshot
+	redo       []snapshot
+	tracking   bool // 正在记录一次操作，内部调用不再重复记录
+}
+
+func New() *Engine {
+	config := DefaultConfig
+	config.Word = DefaultWordSize
+	return &Engine{display: "0", config: config, format: DefaultFormat, radix: 10}
+}
+
+// SetFormat 设置数字的显示格式，包括小数点和千位分隔符
+func (e *Engine) SetFormat(f Format) {
+	e.format = f
+	switch {
+	case e.evaluated && e.err == nil:
+		e.display = e.formatValue(e.ans)
+	case e.entry != "":
+		e.setEntry(e.entry)
+	}
+}
+
+// Format 返回数字的显示格式
+func (e *Engine) Format() Format {
+	return e.format
+}
+
+// SetPrecision 设置除法等运算结果保留的有效数字位数
+func (e *Engine) SetPrecision(digits int) {
+	if digits <= 0 {
+		digits = DefaultPrecision
+	}
+	e.config.Precision = digits
+}
+
+// State 返回当前状态快照
+func (e *Engine) State() State {
+	state := State{
+		Display: e.display,
+		Angle:   e.config.Angle,
+		Mode:    e.mode,
+		Base:    e.base(),
+		Word:    e.config.Word,
+		Memory:  len(e.memory) > 0,
+		CanUndo: len(e.undo) > 0,
+		CanRedo: len(e.redo) > 0,
+		Err:     e.err,
+	}
+	if e.mode == ModeProgrammer {
+		state.Bits = e.config.Word.Pattern(e.currentValue().BigInt())
+	}
+	if e.evaluated {
+		state.Expression = e.expression
+	} else {
+		state.Expression = e.formatExpression(e.pending())
+	}
+	if last := e.last(); isOperator(last) && e.entry == "" && !e.evaluated {
+		state.Operator = last
+	}
+	return state
+}
+
+// SetAngleMode 设置三角函数的角度单位
+func (e *Engine) SetAngleMode(mode AngleMode) {
+	e.config.Angle = mode
+}
+
+// Press 处理一次按键并返回新的状态，改变状态的按键可以撤销
+func (e *Engine) Press(key Key) State {
+	switch key {
+	case KeyUndo:
+		return e.Undo()
+	case KeyRedo:
+		return e.Redo()
+	}
+	e.track(func() { e.press(key) })
+	return e.State()
+}
+
+func (e *Engine) press(key Key) {
+	if e.blocked(key) {
+		return
+	}
+	if name, ok := functionKeys[key]; ok {
+		e.applyFunction(name)
+		return
+	}
+	if op, ok := binaryKeys[key]; ok {
+		e.pressOperator(op)
+		return
+	}
+	if base, ok := baseKeys[key]; ok {
+		e.setBase(base)
+		return
+	}
+	if e.pressMemory(key) {
+		return
+	}
+	if op, ok := postfixKeys[key]; ok {
+		e.continueFromAnswer()
+		e.flushEntry()
+		if e.endsOperand() {
+			e.tokens = append(e.tokens, op)
+		}
+		if op == "%" && e.percentCompletes() {
+			e.calculate()
+		}
+		return
+	}
+
+	switch key {
+	case KeyClear: // 全部清除
+		e.reset()
+	case KeyClearEntry: // 只清除正在输入的数字，保留表达式
+		e.clearEntry()
+	case KeyBackspace: // 退格
+		e.backspace()
+	case KeyNegate: // 正负号
+		e.negate()
+	case KeyEquals:
+		e.calculate()
+	case KeyAngle:
+		e.config.Angle = e.config.Angle.Next()
+	case KeyWord:
+		e.setWordSize(e.config.Word.Next())
+	case KeySign:
+		e.setWordSize(WordSize{Bits: e.config.Word.Bits, Signed: !e.config.Word.Signed})
+	case KeyNot: // 按位取反，与函数一样作用于最后一个操作数
+		e.applyFunction("NOT")
+	case KeyReciprocal: // 1/x 改写为 1 ÷ (x)
+		e.continueFromAnswer()
+		e.flushEntry()
+		if e.endsOperand() {
+			start := e.lastOperandStart()
+			operand := append([]string{"1", "÷", "("}, e.tokens[start:]...)
+			e.tokens = append(e.tokens[:start], append(operand, ")")...)
+		} else {
+			e.tokens = append(e.tokens, "1", "÷")
+		}
+	case KeyPi, KeyE: // 常量
+		if e.evaluated {
+			e.startNew()
+		}
+		e.flushEntry()
+		e.tokens = append(e.tokens, string(key))
+		value, _ := lookupConstant(string(key))
+		e.display = e.formatValue(value)
+	case KeyLParen:
+		if e.evaluated {
+			e.startNew()
+		}
+		e.flushEntry()
+		e.tokens = append(e.tokens, "(")
+	case KeyRParen:
+		e.flushEntry()
+		if e.openParens() > 0 && e.endsOperand() {
+			e.tokens = append(e.tokens, ")")
+		}
+	case KeyDot:
+		if e.mode == ModeProgrammer {
+			return
+		}
+		if e.evaluated {
+			e.startNew()
+		}
+		if e.replace {
+			e.entry = ""
+			e.replace = false
+		}
+		if e.entry == "" {
+			e.setEntry("0.")
+		} else if !strings.Contains(e.entry, ".") {
+			e.setEntry(e.entry + ".")
+		}
+	case Key0, Key1, Key2, Key3, Key4, Key5, Key6, Key7, Key8, Key9, KeyA, KeyB, KeyC, KeyD, KeyE16, KeyF:
+		e.pressDigit(string(key))
+	}
+}
+
+func (e *Engine) pressOperator(op string) {
+	e.continueFromAnswer()
+	e.flushEntry()
+
+	last := e.last()
+	switch {
+	case last == "":
+		// 没有任何输入时以 0 作为左操作数
+		e.tokens = append(e.tokens, "0", op)
+	case last == "(":
+		// 括号后只允许一元负号
+		if op == "-" {
+			e.tokens = append(e.tokens, op)
+		}
+	case isOperator(last):
+		// 连续按运算符时替换前一个
+		e.tokens[len(e.tokens)-1] = op
+	default:
+		e.tokens = append(e.tokens, op)
+	}
+}
+
+func (e *Engine) calculate() {
+	if e.evaluated {
+		// 再次按等号时以结果重复上一次运算：5 + 3 = = 依次得到 8、11
+		if len(e.repeat) == 0 {
+			return
+		}
+		e.continueFromAnswer()
+		e.tokens = append(e.tokens, e.repeat...)
+	}
+	e.flushEntry()
+
+	switch {
+	case len(e.tokens) == 1 && len(e.repeat) > 0 && (isNumber(e.tokens[0]) || isConstant(e.tokens[0])):
+		// 计算后输入新的数字再按等号，对新数字做同样的运算：5 + 3 = 7 = 得到 10
+		e.tokens = append(e.tokens, e.repeat...)
+	case len(e.tokens) > 1 && isOperator(e.last()) && e.openParens() == 0:
+		// 以运算符结尾时用左操作数作为右操作数：5 × = 得到 25
+		operand := e.tokens[operandStart(e.tokens[:len(e.tokens)-1]) : len(e.tokens)-1]
+		e.tokens = append(e.tokens, operand...)
+	}
+
+	// 去掉末尾未完成的运算符和括号，并自动补全右括号
+	for len(e.tokens) > 0 && !e.endsOperand() {
+		e.tokens = e.tokens[:len(e.tokens)-1]
+	}
+	if len(e.tokens) == 0 {
+		return
+	}
+	for i := e.openParens(); i > 0; i-- {
+		e.tokens = append(e.tokens, ")")
+	}
+
+	tokens := e.tokens
+	result, err := e.config.Eval(joinTokens(tokens))
+	e.expression = e.formatExpression(tokens) + " ="
+	e.tokens = nil
+	e.evaluated = true
+	e.repeat = repeatedOperation(tokens)
+	if err != nil {
+		e.display = errorText
+		e.ans = Decimal{}
+		e.err = err
+		e.repeat = nil
+		return
+	}
+	if meaning := e.config.interpret(joinTokens(tokens)); meaning != nil && e.mode != ModeProgrammer {
+		// 百分比运算同时显示解释后的算式：200 + 10% → 200 + 20 =
+		e.expression = e.formatExpression(tokens) + " → " + e.formatExpression(meaning) + " ="
+	}
+	e.display = e.formatValue(result)
+	e.ans = result
+	e.err = nil
+	e.addHistory(tokens, result)
+}
+
+// percentCompletes 判断按 % 后能否立即得出结果。与商业计算器一样，百分数是最外层运算的
+// 右操作数时不必再按等号：200 + 10 % 直接得到 220
+func (e *Engine) percentCompletes() bool {
+	return e.mode != ModeProgrammer && e.openParens() == 0 && e.config.interpret(joinTokens(e.tokens)) != nil
+}
+
+// repeatedOperation 返回表达式最后一个运算符和它的右操作数，没有时返回 nil。
+// 2 + 3 × 4 返回 × 4，2 × (3 + 4) 返回 × (3 + 4)
+func repeatedOperation(tokens []string) []string {
+	start := operandStart(tokens)
+	if start < 2 || !isOperator(tokens[start-1]) {
+		return nil
+	}
+	return slices.Clone(tokens[start-1:])
+}
+
+func (e *Engine) backspace() {
+	switch {
+	case e.evaluated && e.err != nil:
+		// 出错后退格清除错误
+		e.startNew()
+		return
+	case e.evaluated:
+		// 计算完成后退格只清除表达式行
+		e.expression = ""
+		return
+	}
+	if e.entry != "" {
+		runes := []rune(e.entry)
+		entry := string(runes[:len(runes)-1])
+		if entry == "" || entry == "-" {
+			e.entry = ""
+			e.display = "0"
+		} else {
+			e.setEntry(entry)
+		}
+		return
+	}
+	if len(e.tokens) == 0 {
+		return
+	}
+	removed := e.tokens[len(e.tokens)-1]
+	e.tokens = e.tokens[:len(e.tokens)-1]
+	// 函数名和它的左括号一起删除
+	if removed == "(" && isPrefixWord(e.last()) {
+		e.tokens = e.tokens[:len(e.tokens)-1]
+	}
+	// 删除运算符后，前一个数字重新进入编辑状态
+	if last := e.last(); isNumber(last) {
+		e.tokens = e.tokens[:len(e.tokens)-1]
+		e.setEntry(e.entryText(last))
+	}
+}
+
+// clearEntry 清除正在输入的数字；计算完成或出错后清除显示，保留上一次结果
+func (e *Engine) clearEntry() {
+	if e.evaluated {
+		e.startNew()
+		return
+	}
+	e.entry = ""
+	e.replace = false
+	e.display = "0"
+}
+
+func (e *Engine) negate() {
+	if e.evaluated {
+		e.startNew()
+		e.entry = e.entryText(e.ans.String())
+	}
+	switch {
+	case e.entry == "" || e.entry == "0":
+		return
+	case e.mode == ModeProgrammer:
+		// 程序员模式按字长取补码
+		value, _ := parseNumber(e.entryToken())
+		neg := e.config.Word.Wrap(value.Neg().BigInt())
+		e.setEntry(e.entryText(neg.String()))
+	case strings.HasPrefix(e.entry, "-"):
+		e.setEntry(e.entry[1:])
+	default:
+		e.setEntry("-" + e.entry)
+	}
+}
+
+// applyFunction 对最后一个操作数应用函数；还没有操作数时插入 "函数名(" 等待输入
+func (e *Engine) applyFunction(name string) {
+	e.continueFromAnswer()
+	e.flushEntry()
+	if !e.endsOperand() {
+		e.tokens = append(e.tokens, name, "(")
+		return
+	}
+	start := e.lastOperandStart()
+	operand := append([]string{name, "("}, e.tokens[start:]...)
+	e.tokens = append(e.tokens[:start], append(operand, ")")...)
+}
+
+// lastOperandStart 返回最后一个完整操作数的起始下标，包括括号前的函数名和后缀运算符
+func (e *Engine) lastOperandStart() int {
+	return operandStart(e.tokens)
+}
+
+// operandStart 返回词法单元中最后一个操作数的起始位置
+func operandStart(tokens []string) int {
+	i := len(tokens) - 1
+	for i > 0 && isPostfix(tokens[i]) {
+		i--
+	}
+	if tokens[i] == ")" {
+		depth := 0
+		for ; i >= 0; i-- {
+			switch tokens[i] {
+			case ")":
+				depth++
+			case "(":
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if i > 0 && isPrefixWord(tokens[i-1]) {
+			i--
+		}
+	}
+	return max(i, 0)
+}
+
+// blockedByError 出错后不可用的按键：运算符、函数、等号等需要以上一次结果继续计算的按键。
+// 错误状态要先用 AC、CE、⌫ 清除，或直接输入新的数字、常量或左括号开始新的表达式
+func blockedByError(key Key) bool {
+	if _, ok := functionKeys[key]; ok {
+		return true
+	}
+	if _, ok := binaryKeys[key]; ok {
+		return true
+	}
+	if _, ok := postfixKeys[key]; ok {
+		return true
+	}
+	switch key {
+	case KeyEquals, KeyNegate, KeyReciprocal, KeyNot, KeyRParen, KeyMS, KeyMAdd, KeyMSub:
+		return true
+	}
+	return false
+}
+
+// blocked 判断按键是否因错误状态而不可用
+func (e *Engine) blocked(key Key) bool {
+	return e.evaluated && e.err != nil && blockedByError(key)
+}
+
+// Blocked 判断按键是否因错误状态而不可用，界面据此禁用按键
+func (s State) Blocked(key Key) bool {
+	return s.Err != nil && blockedByError(key)
+}
+
+// continueFromAnswer 计算完成后按运算符时，以上一次结果继续计算
+func (e *Engine) continueFromAnswer() {
+	if e.evaluated {
+		e.startNew()
+		e.tokens = []string{e.numberToken(e.ans)}
+		e.display = e.formatValue(e.ans)
+	}
+}
+
+// startNew 开始一个新的表达式，保留上一次的结果
+func (e *Engine) startNew() {
+	e.tokens = nil
+	e.entry = ""
+	e.display = "0"
+	e.expression = ""
+	e.evaluated = false
+	e.err = nil
+	e.replace = false
+}
+
+func (e *Engine) setEntry(entry string) {
+	e.entry = entry
+	if e.base() != 10 {
+		e.display = groupDigits(entry, e.base())
+	} else {
+		e.display = e.format.entry(entry)
+	}
+}
+
+// pressDigit 输入一位数字，程序员模式下忽略当前进制不允许的数字和超出字长的输入
+func (e *Engine) pressDigit(digit string) {
+	if !ValidDigit(Key(digit), e.base()) {
+		return
+	}
+	if e.evaluated {
+		e.startNew()
+	}
+	if e.replace {
+		e.entry = ""
+		e.replace = false
+	}
+	var entry string
+	switch e.entry {
+	case "0":
+		entry = digit
+	case "-0":
+		entry = "-" + digit
+	default:
+		entry = e.entry + digit
+	}
+	if e.mode == ModeProgrammer && !e.fitsWord(entry) {
+		return
+	}
+	e.setEntry(entry)
+}
+
+// flushEntry 把正在输入的数字加入表达式
+func (e *Engine) flushEntry() {
+	if e.entry == "" {
+		return
+	}
+	e.tokens = append(e.tokens, e.entryToken())
+	e.entry = ""
+	e.replace = false
+}
+
+// entryToken 把正在输入的数字转换为表达式中的词法单元，非十进制数字加上进制前缀
+func (e *Engine) entryToken() string {
+	return basePrefixes[e.base()] + strings.TrimSuffix(e.entry, ".")
+}
+
+// pending 返回包含正在输入数字的表达式词法单元
+func (e *Engine) pending() []string {
+	if e.entry == "" {
+		return e.tokens
+	}
+	return append(e.tokens[:len(e.tokens):len(e.tokens)], e.entryToken())
+}
+
+func (e *Engine) last() string {
+	if len(e.tokens) == 0 {
+		return ""
+	}
+	return e.tokens[len(e.tokens)-1]
+}
+
+// endsOperand 判断表达式是否以完整的操作数结尾
+func (e *Engine) endsOperand() bool {
+	last := e.last()
+	return isNumber(last) || last == ")" || isPostfix(last) || isConstant(last)
+}
+
+func (e *Engine) openParens() int {
+	n := 0
+	for _, t := range e.tokens {
+		switch t {
+		case "(":
+			n++
+		case ")":
+			n--
+		}
+	}
+	return n
+}
+
+func (e *Engine) reset() {
+	e.startNew()
+	e.ans = Decimal{}
+	e.repeat = nil
+}
+
+// formatValue 按当前模式格式化计算结果
+func (e *Engine) formatValue(d Decimal) string {
+	if e.mode == ModeProgrammer {
+		return e.format.Int(d.BigInt(), e.base(), e.config.Word)
+	}
+	return e.format.Decimal(d)
+}
+
+func isPostfix(s string) bool {
+	return s == "%" || s == "!" || s == "²"
+}
+
+func isConstant(s string) bool {
+	_, ok := lookupConstant(s)
+	return ok
+}
+
+func isFunction(s string) bool {
+	_, ok := lookupFunction(s)
+	return ok
+}
+
+// isPrefixWord 判断是否为写在括号前的函数名或 NOT
+func isPrefixWord(s string) bool {
+	return isFunction(s) || s == "NOT"
+}
+
+func isNumber(s string) bool {
+	_, err := parseNumber(s)
+	return err == nil
+}
+
+// joinTokens 把词法单元拼接为可解析的表达式，负数加括号以免被当作减号
+func joinTokens(tokens []string) string {
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		if strings.HasPrefix(t, "-") && len(t) > 1 {
+			t = "(" + t + ")"
+		}
+		parts[i] = t
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatExpression 把词法单元拼接为表达式行
+func (e *Engine) formatExpression(tokens []string) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t != ")" && !isPostfix(t) && tokens[i-1] != "(" && !(t == "(" && isPrefixWord(tokens[i-1])) {
+			b.WriteString(" ")
+		}
+		if d, err := ParseDecimal(t); err == nil {
+			// 用户输入的数字保持原样，过长的结果按显示格式缩写
+			switch {
+			case e.mode == ModeProgrammer:
+				// 程序员模式的十进制操作数是整数，按整数格式完整显示
+				t = e.format.Int(d.BigInt(), 10, e.config.Word)
+			case strings.ContainsAny(t, "eE"):
+				t = e.format.localize(t)
+			case len(t) > e.format.maxDigits():
+				t = e.format.Decimal(d)
+			default:
+				t = e.format.entry(t)
+			}
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// keys 把 "1 2 + 3 =" 形式的文字拆成按键序列
+func keys(s string) []Key {
+	var ks []Key
+	for _, f := range strings.Fields(s) {
+		ks = append(ks, Key(f))
+	}
+	return ks
+}
+
+// pressAll 依次按下按键，返回最后的状态
+func pressAll(e *Engine, ks []Key) State {
+	var s State
+	for _, k := range ks {
+		s = e.Press(k)
+	}
+	return s
+}
+
+func TestPress(t *testing.T) {
+	tests := []struct {
+		keys       string
+		display    string
+		expression string
+	}{
+		{"1 2 + 3 4 =", "46", "12 + 34 ="},
+		{"2 + 3 × 4 =", "14", "2 + 3 × 4 ="},
+		{"0 . 1 + 0 . 2 =", "0.3", "0.1 + 0.2 ="},
+		{"1 2 3 4 5 6 7", "1,234,567", "1,234,567"},
+		{"5 ±", "-5", "-5"},
+		{"1 2 3 ⌫", "12", "12"},
+		{"2 + 3 CE 4 =", "6", "2 + 4 ="},
+		{"2 + 3 AC", "0", ""},
+		{"( 1 + 2 ) × 3 =", "9", "(1 + 2) × 3 ="},
+		{"( 1 + 2 × 3 =", "7", "(1 + 2 × 3) ="},
+		{"9 √ =", "3", "√(9) ="},
+		{"2 x² =", "4", "2² ="},
+		{"3 0 sin x² =", "0.25", "sin(30)² ="},
+		// 计算后直接输入数字开始新的表达式，输入运算符以结果继续
+		{"2 + 3 = 4", "4", "4"},
+		{"2 + 3 = × 2 =", "10", "5 × 2 ="},
+	}
+	for _, tt := range tests {
+		s := pressAll(New(), keys(tt.keys))
+		if s.Display != tt.display || s.Expression != tt.expression {
+			t.Errorf("%s: got %q, %q; want %q, %q", tt.keys, s.Display, s.Expression, tt.display, tt.expression)
+		}
+	}
+}
+
+func TestRepeatEquals(t *testing.T) {
+	tests := []struct {
+		keys       string
+		display    string
+		expression string
+	}{
+		{"5 + 3 =", "8", "5 + 3 ="},
+		{"5 + 3 = =", "11", "8 + 3 ="},
+		{"5 + 3 = = =", "14", "11 + 3 ="},
+		{"2 + 3 × 4 = =", "56", "14 × 4 ="},
+		{"2 × ( 3 + 4 ) = =", "98", "14 × (3 + 4) ="},
+		{"5 × =", "25", "5 × 5 ="},
+		{"5 × = =", "125", "25 × 5 ="},
+		// 计算后输入新的数字再按等号，对新数字做同样的运算
+		{"5 + 3 = 7 =", "10", "7 + 3 ="},
+		// 只有一个数字时没有可以重复的运算
+		{"7 = =", "7", "7 ="},
+	}
+	for _, tt := range tests {
+		s := pressAll(New(), keys(tt.keys))
+		if s.Display != tt.display || s.Expression != tt.expression {
+			t.Errorf("%s: got %q, %q; want %q, %q", tt.keys, s.Display, s.Expression, tt.display, tt.expression)
+		}
+	}
+}
+
+func TestPressError(t *testing.T) {
+	e := New()
+	s := pressAll(e, keys("1 ÷ 0 ="))
+	if KindOf(s.Err) != KindDivideByZero {
+		t.Fatalf("1 ÷ 0 = gave error %v, want division by zero", s.Err)
+	}
+	// 出错后运算符和等号不可用
+	for _, k := range []Key{KeyAdd, KeyEquals, KeySqrt, KeyPercent} {
+		if !s.Blocked(k) {
+			t.Errorf("%s not blocked after an error", k)
+		}
+		if got := e.Press(k); got.Err == nil {
+			t.Errorf("pressing %s cleared the error", k)
+		}
+	}
+
+	// 退格清除错误，然后可以正常输入
+	s = e.Press(KeyBackspace)
+	if s.Err != nil || s.Display != "0" || s.Expression != "" {
+		t.Errorf("⌫ after an error: got %q, %q, %v", s.Display, s.Expression, s.Err)
+	}
+	if s = pressAll(e, keys("2 + 2 =")); s.Display != "4" {
+		t.Errorf("after clearing the error 2 + 2 = shows %q", s.Display)
+	}
+
+	// 输入新的数字也会清除错误，且不重复出错的运算
+	s = pressAll(e, keys("1 ÷ 0 = 7 ="))
+	if s.Err != nil || s.Display != "7" {
+		t.Errorf("new number after an error: got %q, %v", s.Display, s.Err)
+	}
+}
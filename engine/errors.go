@@ -0,0 +1,72 @@
+package engine
+
+import "errors"
+
+// ErrorKind 计算错误的类别，界面按类别显示翻译后的说明
+type ErrorKind int
+
+const (
+	KindOther         ErrorKind = iota // 不属于以下类别的错误
+	KindSyntax                         // 表达式无法解析
+	KindDivideByZero                   // 除数为零
+	KindOverflow                       // 结果超出可表示的范围
+	KindDomain                         // 参数超出函数定义域
+	KindPrecisionLoss                  // 数值超出浮点数能精确计算的范围
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindSyntax:
+		return "syntax"
+	case KindDivideByZero:
+		return "divide by zero"
+	case KindOverflow:
+		return "overflow"
+	case KindDomain:
+		return "domain"
+	case KindPrecisionLoss:
+		return "precision loss"
+	default:
+		return "other"
+	}
+}
+
+// Error 计算错误，可以用 errors.Is 与下面的错误值比较，或用 KindOf 取得类别
+type Error struct {
+	kind ErrorKind
+	msg  string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Kind 返回错误的类别
+func (e *Error) Kind() ErrorKind {
+	return e.kind
+}
+
+var (
+	// ErrDivideByZero 除数为零
+	ErrDivideByZero = &Error{KindDivideByZero, "division by zero"}
+	// ErrOverflow 结果超出可表示的范围
+	ErrOverflow = &Error{KindOverflow, "numeric overflow"}
+	// ErrDomain 参数超出函数定义域，如 ln(-1)、asin(2)
+	ErrDomain = &Error{KindDomain, "math domain error"}
+	// ErrPrecisionLoss 参数超出 float64 的范围或精度，函数无法得到有意义的结果，如 sin(10^20)、ln(10^-400)
+	ErrPrecisionLoss = &Error{KindPrecisionLoss, "precision loss"}
+)
+
+// Kind 语法错误的类别
+func (e *SyntaxError) Kind() ErrorKind {
+	return KindSyntax
+}
+
+// KindOf 返回错误的类别，err 为 nil 或不是计算错误时返回 KindOther
+func KindOf(err error) ErrorKind {
+	var kinded interface{ Kind() ErrorKind }
+	if errors.As(err, &kinded) {
+		return kinded.Kind()
+	}
+	return KindOther
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Config 计算配置
+type Config struct {
+	Precision int                // 除法等无法精确表示的结果保留的有效数字位数
+	Angle     AngleMode          // 三角函数的角度单位
+	Integer   bool               // 程序员模式：每一步结果都截断为整数并按字长回绕
+	Word      WordSize           // 程序员模式的字长
+	Vars      map[string]Decimal // 表达式中可用的变量，如 REPL 中的 ans
+}
+
+// DefaultConfig 默认计算配置
+var DefaultConfig = Config{Precision: DefaultPrecision, Angle: Degrees}
+
+func (c Config) precision() int {
+	if c.Precision <= 0 {
+		return DefaultPrecision
+	}
+	return min(c.Precision, MaxPrecision)
+}
+
+// Eval 使用默认配置解析并计算表达式
+func Eval(input string) (Decimal, error) {
+	return DefaultConfig.Eval(input)
+}
+
+// Eval 解析并计算表达式
+func (c Config) Eval(input string) (Decimal, error) {
+	node, err := parse(input, c.Vars)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return c.Evaluate(node)
+}
+
+// Evaluate 计算语法树的值
+func (c Config) Evaluate(node Node) (Decimal, error) {
+	d, err := c.evaluate(node)
+	if err == nil {
+		err = d.checkRange()
+	}
+	if err != nil || !c.Integer {
+		return d, err
+	}
+	// 整数除法向零截断，溢出按字长回绕
+	return NewDecimalFromBigInt(c.word().Wrap(d.BigInt())), nil
+}
+
+func (c Config) evaluate(node Node) (Decimal, error) {
+	switch n := node.(type) {
+	case *Number:
+		return parseNumber(n.Text)
+	case *Const:
+		return n.Value.Round(c.precision()), nil
+	case *Call:
+		x, err := c.Evaluate(n.Arg)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return n.fn(c, x)
+	case *Unary:
+		x, err := c.Evaluate(n.X)
+		if err != nil {
+			return Decimal{}, err
+		}
+		switch n.Op {
+		case "-":
+			return x.Neg(), nil
+		case "NOT":
+			return c.not(x)
+		}
+		return x, nil
+	case *Postfix:
+		x, err := c.Evaluate(n.X)
+		if err != nil {
+			return Decimal{}, err
+		}
+		switch n.Op {
+		case "!":
+			return factorial(x)
+		case "²":
+			return checkedMul(x, x)
+		}
+		return x.Shift(-2), nil
+	case *Binary:
+		left, right, err := c.operands(n)
+		if err != nil {
+			return Decimal{}, err
+		}
+		switch n.Op {
+		case "+":
+			return left.Add(right), nil
+		case "-":
+			return left.Sub(right), nil
+		case "×":
+			return checkedMul(left, right)
+		case "÷":
+			return left.Quo(right, c.precision())
+		case "^":
+			return power(c, left, right)
+		case "ʸ√":
+			return root(c, left, right)
+		case "Δ%":
+			return percentChange(c, left, right)
+		case "MU":
+			return checkedMul(left, markupFactor(right))
+		case "MG":
+			return left.Quo(marginFactor(right), c.precision())
+		default:
+			return c.bitwise(n.Op, left, right)
+		}
+	}
+	return Decimal{}, fmt.Errorf("unknown node %T", node)
+}
+
+// CheckVariable 检查变量名：只能由字母组成，且不能与常量、函数或单词运算符同名
+func CheckVariable(name string) error {
+	if name == "" {
+		return errors.New("missing variable name")
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			return fmt.Errorf("invalid variable name %q: only letters are allowed", name)
+		}
+	}
+	_, constant := lookupConstant(name)
+	_, function := lookupFunction(name)
+	if constant || function || wordOperators[strings.ToUpper(name)] {
+		return fmt.Errorf("%q is a built-in name", name)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package engine
+
+import "testing"
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// 精确的十进制运算
+		{"0.1 + 0.2", "0.3"},
+		{"1 - 0.9", "0.1"},
+		{"1.1 × 1.1", "1.21"},
+		{"1 ÷ 4", "0.25"},
+		// 优先级和结合性
+		{"2 + 3 × 4", "14"},
+		{"(2 + 3) × 4", "20"},
+		{"10 - 4 - 3", "3"},
+		{"2 ^ 3 ^ 2", "512"},
+		{"-2 ^ 2", "-4"},
+		{"2 ^ -1", "0.5"},
+		{"3!", "6"},
+		{"3² + 4²", "25"},
+		// 隐式乘法
+		{"2(3 + 4)", "14"},
+		{"(1 + 2)(3 + 4)", "21"},
+		{"(1 + 2)3", "9"},
+		{"2 sqrt(9)", "6"},
+		// 函数的括号参数之后的后缀运算和乘方作用于函数值
+		{"sin(30)²", "0.25"},
+		{"sin(30)^2", "0.25"},
+		{"ln(e)^2", "1"},
+		{"sqrt(4)!", "2"},
+		{"sqrt(4)(1 + 2)", "6"},
+		{"sqrt 4²", "4"},
+		{"2π", "6.283185307179586476925286766559006"},
+		// 指数形式的数字
+		{"1e3", "1000"},
+		{".5e2", "50"},
+		{"2.5E-3", "0.0025"},
+		{"1e+22 × 1000", "10000000000000000000000000"},
+		// e 后没有数字时是常数 e
+		{"2e", "5.436563656918090470720574942705324"},
+		{"3e2e", "815.4845485377135706080862414057986"},
+		{"2e-1", "0.2"},
+	}
+	var c Config
+	for _, tt := range tests {
+		got, err := c.Eval(tt.input)
+		if err != nil {
+			t.Errorf("Eval(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Eval(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  ErrorKind
+	}{
+		{"1 ÷ 0", KindDivideByZero},
+		{"ln(-1)", KindDomain},
+		{"sqrt(-4)", KindDomain},
+		{"sin(10^20 + 1)", KindPrecisionLoss},
+		{"10 ^ 400000", KindOverflow},
+		{"12 ^ 5000000000000000000", KindOverflow},
+		{"10 ^ 100000 × 10 ^ 100000", KindOverflow},
+		{"(10 ^ 60000)²", KindOverflow},
+		{"1 +", KindSyntax},
+		{"(1 + 2", KindSyntax},
+		{"2 3", KindSyntax},
+		{"1e100001", KindOverflow},
+		{"1ex", KindSyntax},
+		{"2e+", KindSyntax},
+	}
+	var c Config
+	for _, tt := range tests {
+		_, err := c.Eval(tt.input)
+		if err == nil {
+			t.Errorf("Eval(%q) succeeded, want error", tt.input)
+			continue
+		}
+		if got := KindOf(err); got != tt.kind {
+			t.Errorf("Eval(%q) error %v has kind %v, want %v", tt.input, err, got, tt.kind)
+		}
+	}
+}
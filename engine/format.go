@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Notation 数字超出显示位数时使用的记数法
+type Notation int
+
+const (
+	// NotationScientific 科学记数法，尾数整数部分只有一位：1.5e+21
+	NotationScientific Notation = iota
+	// NotationEngineering 工程记数法，指数为 3 的倍数：15e+21
+	NotationEngineering
+)
+
+// Format 数字显示格式
+type Format struct {
+	MaxDigits int      // 普通记数法最多显示的数字位数，超出后改用指数形式
+	Notation  Notation // 超出位数时使用的记数法
+	Grouping  bool     // 整数部分是否添加千位分隔符
+	Point     rune     // 小数点，0 表示 '.'
+	Group     rune     // 千位分隔符，0 表示 ','
+	Myriad    bool     // 整数部分每 4 位一组并使用万、亿单位代替千位分隔符，如 1亿2345万6789
+}
+
+// DefaultFormat 默认显示格式
+var DefaultFormat = Format{
+	MaxDigits: 16,
+	Notation:  NotationScientific,
+	Grouping:  true,
+}
+
+// FormatNumber 使用默认显示格式格式化数字
+func FormatNumber(num Decimal) string {
+	return DefaultFormat.Decimal(num)
+}
+
+// Decimal 格式化十进制数
+//
+// 能在 MaxDigits 位内显示时使用普通记数法（必要时舍入小数部分），
+// 整数部分过长或小数点后的前导 0 过多时改用科学或工程记数法。
+func (f Format) Decimal(d Decimal) string {
+	maxDigits := f.maxDigits()
+	d = d.Round(maxDigits)
+	digits, pointPos := d.Digits()
+	if pointPos > maxDigits {
+		return f.exponent(d, maxDigits)
+	}
+
+	// 小数部分可用的位数
+	places := maxDigits - max(pointPos, 0)
+	if pointPos <= 0 {
+		// 舍入后保留的有效数字太少时改用指数形式
+		kept := places + pointPos
+		if kept < len(digits) && kept < maxDigits/2 {
+			return f.exponent(d, maxDigits)
+		}
+	}
+	if d.exp < -places {
+		d = d.roundAt(-places)
+	}
+	return f.plain(d.String())
+}
+
+// Full 按完整精度格式化十进制数，不舍入也不改用指数形式
+func (f Format) Full(d Decimal) string {
+	return f.plain(d.String())
+}
+
+func (f Format) maxDigits() int {
+	if f.MaxDigits <= 0 {
+		return DefaultFormat.MaxDigits
+	}
+	return f.MaxDigits
+}
+
+// plain 为普通记数法的文字添加千位分隔符，并使用设置的小数点
+func (f Format) plain(s string) string {
+	if !f.Grouping {
+		return f.localize(s)
+	}
+	return f.entry(s)
+}
+
+func (f Format) point() rune {
+	if f.Point == 0 {
+		return '.'
+	}
+	return f.Point
+}
+
+func (f Format) group() rune {
+	if f.Group == 0 {
+		return ','
+	}
+	return f.Group
+}
+
+// localize 把 '.' 小数点替换为设置的小数点
+func (f Format) localize(s string) string {
+	if f.point() == '.' {
+		return s
+	}
+	return strings.Replace(s, ".", string(f.point()), 1)
+}
+
+// entry 格式化正在输入的数字，保留用户输入的小数位
+func (f Format) entry(entry string) string {
+	negative := strings.HasPrefix(entry, "-")
+	entry = strings.TrimPrefix(entry, "-")
+	intPart, frac, hasDot := strings.Cut(entry, ".")
+	result := f.addGroups(intPart)
+	if f.Myriad {
+		if grouped, ok := myriadGroups(intPart, hasDot); ok {
+			result = grouped
+		}
+	}
+	if hasDot {
+		result += string(f.point()) + frac
+	}
+	if negative {
+		return "-" + result
+	}
+	return result
+}
+
+// addGroups 每三位数字插入千位分隔符
+func (f Format) addGroups(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+	var result strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			result.WriteRune(f.group())
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
+// delocalize 把使用本格式分隔符的数字或表达式转换为 '.' 小数点、不带千位分隔符的形式
+func (f Format) delocalize(s string) string {
+	point, group := f.point(), f.group()
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case group:
+			return -1
+		case point:
+			return '.'
+		}
+		return r
+	}, s)
+}
+
+// exponent 用指数形式格式化，尾数保留 maxDigits 位有效数字
+func (f Format) exponent(d Decimal, maxDigits int) string {
+	digits, pointPos := d.Round(maxDigits).Digits()
+	exp := pointPos - 1
+	intDigits := 1
+	if f.Notation == NotationEngineering {
+		// 指数向下取整到 3 的倍数，尾数整数部分为 1 到 3 位
+		shift := ((exp % 3) + 3) % 3
+		exp -= shift
+		intDigits += shift
+	}
+	if len(digits) < intDigits {
+		digits += strings.Repeat("0", intDigits-len(digits))
+	}
+
+	var b strings.Builder
+	if d.Sign() < 0 {
+		b.WriteString("-")
+	}
+	b.WriteString(digits[:intDigits])
+	if len(digits) > intDigits {
+		b.WriteRune(f.point())
+		b.WriteString(digits[intDigits:])
+	}
+	b.WriteString("e")
+	if exp >= 0 {
+		b.WriteString("+")
+	}
+	b.WriteString(strconv.Itoa(exp))
+	return b.String()
+}
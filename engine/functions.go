@@ -0,0 +1,373 @@
+package engine
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// AngleMode 三角函数使用的角度单位
+type AngleMode int
+
+const (
+	Degrees AngleMode = iota
+	Radians
+	Gradians
+)
+
+func (m AngleMode) String() string {
+	switch m {
+	case Radians:
+		return "RAD"
+	case Gradians:
+		return "GRAD"
+	default:
+		return "DEG"
+	}
+}
+
+// Next 返回 DEG → RAD → GRAD 循环中的下一个单位
+func (m AngleMode) Next() AngleMode {
+	return (m + 1) % 3
+}
+
+// fullTurn 一周对应的角度值，弧度没有精确的十进制表示
+func (m AngleMode) fullTurn() (Decimal, bool) {
+	switch m {
+	case Degrees:
+		return NewDecimal(360), true
+	case Gradians:
+		return NewDecimal(400), true
+	}
+	return Decimal{}, false
+}
+
+// toRadians 把当前单位的角度转换为弧度
+func (m AngleMode) toRadians(x float64) float64 {
+	switch m {
+	case Degrees:
+		return x * math.Pi / 180
+	case Gradians:
+		return x * math.Pi / 200
+	}
+	return x
+}
+
+// fromRadians 把弧度转换为当前单位的角度
+func (m AngleMode) fromRadians(x float64) float64 {
+	switch m {
+	case Degrees:
+		return x * 180 / math.Pi
+	case Gradians:
+		return x * 200 / math.Pi
+	}
+	return x
+}
+
+// floatDigits 由 float64 计算得到的结果保留的有效数字位数，
+// 去掉二进制浮点运算带来的末位误差，如 sin(30°) = 0.49999999999999994
+const floatDigits = 15
+
+// 数学常量，位数足够覆盖默认精度
+var (
+	constPi, _ = ParseDecimal("3.14159265358979323846264338327950288419716939937510")
+	constE, _  = ParseDecimal("2.71828182845904523536028747135266249775724709369995")
+)
+
+// constants 常量名到值的映射
+var constants = map[string]Decimal{
+	"π":  constPi,
+	"pi": constPi,
+	"e":  constE,
+}
+
+// mathFunc 单参数数学函数
+type mathFunc func(c Config, x Decimal) (Decimal, error)
+
+// functionAliases 把按键标签和 ASCII 写法统一成函数名
+var functionAliases = map[string]string{
+	"sin⁻¹":  "asin",
+	"cos⁻¹":  "acos",
+	"tan⁻¹":  "atan",
+	"sinh⁻¹": "asinh",
+	"cosh⁻¹": "acosh",
+	"tanh⁻¹": "atanh",
+	"log₂":   "log2",
+	"√":      "sqrt",
+}
+
+// functions 函数名到实现的映射
+var functions = map[string]mathFunc{
+	"sin":   trig(math.Sin, 0),
+	"cos":   trig(math.Cos, 1),
+	"tan":   tangent,
+	"asin":  inverseTrig(math.Asin),
+	"acos":  inverseTrig(math.Acos),
+	"atan":  inverseTrig(math.Atan),
+	"sinh":  floatFunc(math.Sinh),
+	"cosh":  floatFunc(math.Cosh),
+	"tanh":  floatFunc(math.Tanh),
+	"asinh": floatFunc(math.Asinh),
+	"acosh": floatFunc(math.Acosh),
+	"atanh": atanh,
+	"ln":    logarithm(math.Log),
+	"log":   logarithm(math.Log10),
+	"log2":  logarithm(math.Log2),
+	"sqrt":  sqrt,
+	"abs":   func(c Config, x Decimal) (Decimal, error) { return x.Abs(), nil },
+}
+
+// lookupFunction 按名字查找函数，名字不区分大小写
+func lookupFunction(name string) (mathFunc, bool) {
+	name = strings.ToLower(name)
+	if canonical, ok := functionAliases[name]; ok {
+		name = canonical
+	}
+	f, ok := functions[name]
+	return f, ok
+}
+
+// lookupConstant 按名字查找常量
+func lookupConstant(name string) (Decimal, bool) {
+	if name != "e" {
+		name = strings.ToLower(name)
+	}
+	d, ok := constants[name]
+	return d, ok
+}
+
+// maxTrigArg 三角函数参数的上限 2^53，更大的数在 float64 中相邻值的间隔超过一周，结果没有意义
+const maxTrigArg = 1 << 53
+
+// toFloat 把参数转换为 float64 计算，超出 float64 的范围时返回 ErrPrecisionLoss，
+// 而不是变成 0 或无穷大后继续计算
+func toFloat(x Decimal) (float64, error) {
+	f, err := strconv.ParseFloat(x.Scientific(), 64)
+	if err != nil || f == 0 && !x.IsZero() {
+		return 0, ErrPrecisionLoss
+	}
+	return f, nil
+}
+
+// toAngle 把三角函数的参数转换为弧度
+func toAngle(c Config, x Decimal) (float64, error) {
+	f, err := toFloat(x)
+	if err != nil {
+		return 0, err
+	}
+	if math.Abs(f) >= maxTrigArg {
+		return 0, ErrPrecisionLoss
+	}
+	return c.Angle.toRadians(f), nil
+}
+
+// fromFloat 把 float64 计算结果转换为 Decimal
+func fromFloat(v float64) (Decimal, error) {
+	d, err := NewDecimalFromFloat(v)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return d.Round(floatDigits), nil
+}
+
+func floatFunc(f func(float64) float64) mathFunc {
+	return func(c Config, x Decimal) (Decimal, error) {
+		v, err := toFloat(x)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return fromFloat(f(v))
+	}
+}
+
+// trig 正弦和余弦，角度为直角整数倍时直接返回精确值。
+// offset 为函数相对正弦的相位差（以直角计）：sin 为 0，cos 为 1
+func trig(f func(float64) float64, offset int) mathFunc {
+	return func(c Config, x Decimal) (Decimal, error) {
+		if n, ok := quarterTurns(c.Angle, x); ok {
+			values := []int64{0, 1, 0, -1}
+			return NewDecimal(values[(n+offset)%4]), nil
+		}
+		v, err := toAngle(c, x)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return fromFloat(snapZero(f(v)))
+	}
+}
+
+func tangent(c Config, x Decimal) (Decimal, error) {
+	if n, ok := quarterTurns(c.Angle, x); ok {
+		if n%2 == 1 {
+			return Decimal{}, ErrDomain
+		}
+		return Decimal{}, nil
+	}
+	v, err := toAngle(c, x)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return fromFloat(snapZero(math.Tan(v)))
+}
+
+func inverseTrig(f func(float64) float64) mathFunc {
+	return func(c Config, x Decimal) (Decimal, error) {
+		v, err := toFloat(x)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return fromFloat(c.Angle.fromRadians(f(v)))
+	}
+}
+
+func atanh(c Config, x Decimal) (Decimal, error) {
+	if x.Abs().Cmp(NewDecimal(1)) >= 0 {
+		return Decimal{}, ErrDomain
+	}
+	v, err := toFloat(x)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return fromFloat(math.Atanh(v))
+}
+
+func logarithm(f func(float64) float64) mathFunc {
+	return func(c Config, x Decimal) (Decimal, error) {
+		if x.Sign() <= 0 {
+			return Decimal{}, ErrDomain
+		}
+		v, err := toFloat(x)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return fromFloat(f(v))
+	}
+}
+
+// quarterTurns 角度为直角整数倍时返回直角个数（0 到 3）
+func quarterTurns(mode AngleMode, x Decimal) (int, bool) {
+	turn, ok := mode.fullTurn()
+	if !ok {
+		return 0, false
+	}
+	quarter, _ := turn.Quo(NewDecimal(4), DefaultPrecision)
+	n, err := x.Quo(quarter, DefaultPrecision)
+	if err != nil || !n.IsInt() {
+		return 0, false
+	}
+	m := new(big.Int).Mod(n.BigInt(), big.NewInt(4))
+	return int(m.Int64()), true
+}
+
+// snapZero 把浮点误差导致的极小值归零，如 sin(π) = 1.2e-16
+func snapZero(v float64) float64 {
+	if math.Abs(v) < 1e-15 {
+		return 0
+	}
+	return v
+}
+
+// sqrt 平方根，使用 big.Float 按配置精度计算，完全平方数得到精确结果
+func sqrt(c Config, x Decimal) (Decimal, error) {
+	if x.Sign() < 0 {
+		return Decimal{}, ErrDomain
+	}
+	if x.IsZero() {
+		return x, nil
+	}
+	prec := uint(c.precision())*4 + 64
+	f, _, err := big.ParseFloat(x.String(), 10, prec, big.ToNearestEven)
+	if err != nil {
+		return Decimal{}, err
+	}
+	f.Sqrt(f)
+	d, err := ParseDecimal(f.Text('e', c.precision()+2))
+	if err != nil {
+		return Decimal{}, err
+	}
+	return d.Round(c.precision()), nil
+}
+
+// maxFactorial 阶乘参数上限，避免计算过大的整数
+const maxFactorial = 10000
+
+// factorial n!，只接受非负整数
+func factorial(x Decimal) (Decimal, error) {
+	if x.Sign() < 0 || !x.IsInt() {
+		return Decimal{}, ErrDomain
+	}
+	n := x.BigInt()
+	if n.Cmp(big.NewInt(maxFactorial)) > 0 {
+		return Decimal{}, ErrOverflow
+	}
+	return NewDecimalFromBigInt(new(big.Int).MulRange(1, n.Int64())), nil
+}
+
+// maxPowerDigits 整数次幂结果的最大位数
+const maxPowerDigits = 100000
+
+// power x^y，整数次幂精确计算，其余使用 float64
+func power(c Config, x, y Decimal) (Decimal, error) {
+	if y.IsInt() {
+		n := y.BigInt()
+		if x.IsZero() {
+			if n.Sign() < 0 {
+				return Decimal{}, ErrDivideByZero
+			}
+			if n.Sign() == 0 {
+				return NewDecimal(1), nil
+			}
+			return Decimal{}, nil
+		}
+		abs := new(big.Int).Abs(n)
+		digits, pointPos := x.Digits()
+		size := int64(max(len(digits), pointPos, -pointPos))
+		if !abs.IsInt64() || abs.Int64() > maxPowerDigits/size {
+			return Decimal{}, ErrOverflow
+		}
+		result := x.powInt(abs.Int64())
+		if n.Sign() < 0 {
+			return NewDecimal(1).Quo(result, c.precision())
+		}
+		return result, nil
+	}
+	if x.Sign() < 0 {
+		return Decimal{}, ErrDomain
+	}
+	return floatPower(x, y, false)
+}
+
+// root x 的 y 次方根，负数只能开奇数次方根
+func root(c Config, x, y Decimal) (Decimal, error) {
+	if y.IsZero() {
+		return Decimal{}, ErrDomain
+	}
+	if y.Cmp(NewDecimal(2)) == 0 {
+		return sqrt(c, x)
+	}
+	if x.Sign() < 0 {
+		if !y.IsInt() || new(big.Int).Mod(y.BigInt(), big.NewInt(2)).Sign() == 0 {
+			return Decimal{}, ErrDomain
+		}
+		r, err := root(c, x.Neg(), y)
+		return r.Neg(), err
+	}
+	return floatPower(x, y, true)
+}
+
+// floatPower 用 float64 计算 x^y，inverse 为真时计算 x^(1/y)
+func floatPower(x, y Decimal, inverse bool) (Decimal, error) {
+	fx, err := toFloat(x)
+	if err != nil {
+		return Decimal{}, err
+	}
+	fy, err := toFloat(y)
+	if err != nil {
+		return Decimal{}, err
+	}
+	if inverse {
+		fy = 1 / fy
+	}
+	return fromFloat(math.Pow(fx, fy))
+}
@@ -0,0 +1,107 @@
+package engine
+
+import "time"
+
+// HistoryLimit 保留的历史记录条数上限，超出后丢弃最早的记录
+const HistoryLimit = 500
+
+// HistoryEntry 一次完成的计算
+type HistoryEntry struct {
+	Expression string    // 显示用的表达式，不含等号
+	Input      string    // 可重新解析的表达式
+	Result     Decimal   // 计算结果
+	Display    string    // 按当时的显示格式格式化的结果
+	Time       time.Time // 计算时间
+	Mode       Mode      // 计算时的模式
+}
+
+// History 返回全部计算历史，最早的在前
+func (e *Engine) History() []HistoryEntry {
+	return append([]HistoryEntry(nil), e.history...)
+}
+
+// SetHistory 替换计算历史，如启动时载入保存的记录
+func (e *Engine) SetHistory(entries []HistoryEntry) {
+	e.history = append([]HistoryEntry(nil), trimHistory(entries)...)
+}
+
+// ClearHistory 清空计算历史
+func (e *Engine) ClearHistory() {
+	e.history = nil
+}
+
+// OnHistory 设置每次记录新的计算历史时调用的函数
+func (e *Engine) OnHistory(f func(HistoryEntry)) {
+	e.onHistory = f
+}
+
+// addHistory 记录一次成功的计算
+func (e *Engine) addHistory(tokens []string, result Decimal) {
+	h := HistoryEntry{
+		Expression: e.formatExpression(tokens),
+		Input:      joinTokens(tokens),
+		Result:     result,
+		Display:    e.formatValue(result),
+		Time:       time.Now(),
+		Mode:       e.mode,
+	}
+	e.history = trimHistory(append(e.history, h))
+	if e.onHistory != nil {
+		e.onHistory(h)
+	}
+}
+
+// trimHistory 只保留最近的 HistoryLimit 条记录
+func trimHistory(entries []HistoryEntry) []HistoryEntry {
+	if len(entries) > HistoryLimit {
+		return entries[len(entries)-HistoryLimit:]
+	}
+	return entries
+}
+
+// RecallResult 把历史记录的结果作为正在输入的数字
+func (e *Engine) RecallResult(h HistoryEntry) State {
+	e.track(func() { e.recall(h.Result) })
+	return e.State()
+}
+
+// RecallExpression 用历史记录的表达式替换当前表达式，可继续编辑后重新计算
+func (e *Engine) RecallExpression(h HistoryEntry) State {
+	tokens, err := splitTokens(h.Input)
+	if err == nil && len(tokens) > 0 {
+		e.track(func() { e.loadExpression(tokens) })
+	}
+	return e.State()
+}
+
+// loadExpression 用词法单元替换当前表达式，最后一个数字重新进入编辑状态
+func (e *Engine) loadExpression(tokens []string) {
+	e.startNew()
+	e.tokens = tokens
+	if last := e.last(); isNumber(last) {
+		e.tokens = e.tokens[:len(e.tokens)-1]
+		e.setEntry(e.entryText(last))
+	} else {
+		e.display = e.formatValue(e.currentValue())
+	}
+}
+
+// splitTokens 把 joinTokens 生成的表达式拆回引擎使用的词法单元，
+// 括号中的负数 (-5) 合并为一个数字
+func splitTokens(input string) ([]string, error) {
+	toks, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []string
+	for i := 0; i < len(toks) && toks[i].kind != tokEOF; i++ {
+		if i+3 < len(toks) && toks[i].kind == tokLParen && toks[i+1].text == "-" &&
+			toks[i+2].kind == tokNumber && toks[i+3].kind == tokRParen {
+			tokens = append(tokens, "-"+toks[i+2].text)
+			i += 3
+			continue
+		}
+		tokens = append(tokens, toks[i].text)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokOperator
+	tokPostfix
+	tokIdent
+	tokLParen
+	tokRParen
+)
+
+// token 词法单元，Pos 为其在输入中的字符列（从 0 开始）
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// SyntaxError 表达式语法错误
+type SyntaxError struct {
+	Pos int    // 出错位置（字符列，从 0 开始）
+	Msg string // 错误描述
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.Pos+1, e.Msg)
+}
+
+// operatorAliases 把常见的 ASCII 写法统一成显示用的运算符
+var operatorAliases = map[rune]string{
+	'+': "+",
+	'-': "-",
+	'−': "-",
+	'*': "×",
+	'×': "×",
+	'/': "÷",
+	'÷': "÷",
+	'^': "^",
+}
+
+// wordOperators 以单词表示的运算符：程序员模式的位运算和取模，以及加价 MU 和毛利 MG，不区分大小写
+var wordOperators = map[string]bool{
+	"AND":  true,
+	"OR":   true,
+	"XOR":  true,
+	"NAND": true,
+	"NOR":  true,
+	"NOT":  true,
+	"MOD":  true,
+	"ROL":  true,
+	"ROR":  true,
+	"MU":   true,
+	"MG":   true,
+}
+
+// postfixOperators 后缀运算符：百分号、阶乘、平方
+var postfixOperators = map[rune]string{
+	'%': "%",
+	'!': "!",
+	'²': "²",
+}
+
+// isIdentRune 判断字符能否组成函数名或常量名，如 sin⁻¹、log₂、π
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '⁻' || r == '¹' || r == '₂'
+}
+
+// tokenize 把表达式拆分为词法单元
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '0' && i+2 < len(runes) && strings.ContainsRune("xXoObB", runes[i+1]) && isHexDigit(runes[i+2]):
+			// 带进制前缀的整数：0xFF、0o17、0b1010
+			start := i
+			i += 2
+			for i < len(runes) && isHexDigit(runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			if _, ok := parseInt(text); !ok {
+				return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, pos: start})
+		case (r == '<' || r == '>') && i+1 < len(runes) && runes[i+1] == r:
+			tokens = append(tokens, token{kind: tokOperator, text: string([]rune{r, r}), pos: i})
+			i += 2
+		case r >= '0' && r <= '9' || r == '.':
+			start := i
+			dots := 0
+			for i < len(runes) && (runes[i] >= '0' && runes[i] <= '9' || runes[i] == '.') {
+				if runes[i] == '.' {
+					dots++
+				}
+				i++
+			}
+			// 指数部分：1e+22、2.5E-3。e 后没有数字时 e 是常数，2e 为 2×e
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				digits := j
+				for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+					j++
+				}
+				if j > digits {
+					i = j
+				}
+			}
+			text := string(runes[start:i])
+			if dots > 1 || text == "." {
+				return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			if _, err := ParseDecimal(text); err != nil {
+				if errors.Is(err, ErrOverflow) {
+					return nil, err
+				}
+				return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, pos: start})
+		case r == 'Δ' && i+1 < len(runes) && runes[i+1] == '%':
+			tokens = append(tokens, token{kind: tokOperator, text: "Δ%", pos: i})
+			i += 2
+		case r == 'ʸ' && i+1 < len(runes) && runes[i+1] == '√':
+			tokens = append(tokens, token{kind: tokOperator, text: "ʸ√", pos: i})
+			i += 2
+		case r == '√':
+			tokens = append(tokens, token{kind: tokIdent, text: "√", pos: i})
+			i++
+		case isIdentRune(r):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			// log2 是唯一名字里带数字的函数
+			if strings.EqualFold(text, "log") && i < len(runes) && runes[i] == '2' {
+				text += "2"
+				i++
+			}
+			if upper := strings.ToUpper(text); wordOperators[upper] {
+				tokens = append(tokens, token{kind: tokOperator, text: upper, pos: start})
+				continue
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: text, pos: start})
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		default:
+			if op, ok := postfixOperators[r]; ok {
+				tokens = append(tokens, token{kind: tokPostfix, text: op, pos: i})
+				i++
+				continue
+			}
+			op, ok := operatorAliases[r]
+			if !ok {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokOperator, text: op, pos: i})
+			i++
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+func isHexDigit(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+// isOperator 判断文字是否为二元运算符
+func isOperator(s string) bool {
+	_, ok := precedences[s]
+	return ok
+}
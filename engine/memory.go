@@ -0,0 +1,145 @@
+package engine
+
+import "fmt"
+
+// MemorySlot 一个命名的存储单元
+type MemorySlot struct {
+	Name  string
+	Value Decimal
+}
+
+// Memory 返回全部存储单元，最近存入的在前
+func (e *Engine) Memory() []MemorySlot {
+	return append([]MemorySlot(nil), e.memory...)
+}
+
+// SetMemory 替换全部存储单元，如启动时载入保存的存储
+func (e *Engine) SetMemory(slots []MemorySlot) {
+	e.memory = append([]MemorySlot(nil), slots...)
+}
+
+// OnMemory 设置存储单元改变时调用的函数
+func (e *Engine) OnMemory(f func([]MemorySlot)) {
+	e.onMemory = f
+}
+
+// MemoryRecall 把第 i 个存储单元的值作为正在输入的数字
+func (e *Engine) MemoryRecall(i int) State {
+	e.track(func() {
+		if i >= 0 && i < len(e.memory) {
+			e.recall(e.memory[i].Value)
+		}
+	})
+	return e.State()
+}
+
+// MemoryAdd 把当前值加到第 i 个存储单元
+func (e *Engine) MemoryAdd(i int) State {
+	e.track(func() { e.updateMemory(i, Decimal.Add) })
+	return e.State()
+}
+
+// MemorySubtract 从第 i 个存储单元中减去当前值
+func (e *Engine) MemorySubtract(i int) State {
+	e.track(func() { e.updateMemory(i, Decimal.Sub) })
+	return e.State()
+}
+
+// MemoryClear 删除第 i 个存储单元
+func (e *Engine) MemoryClear(i int) State {
+	e.track(func() {
+		if i >= 0 && i < len(e.memory) {
+			e.memory = append(e.memory[:i:i], e.memory[i+1:]...)
+			e.memoryChanged()
+		}
+	})
+	return e.State()
+}
+
+// RenameMemory 修改第 i 个存储单元的名字
+func (e *Engine) RenameMemory(i int, name string) {
+	if i >= 0 && i < len(e.memory) && e.memory[i].Name != name {
+		e.memory[i].Name = name
+		e.memoryChanged()
+	}
+}
+
+// pressMemory 处理存储按键，按键不是存储按键时返回 false
+func (e *Engine) pressMemory(key Key) bool {
+	switch key {
+	case KeyMC:
+		if len(e.memory) > 0 {
+			e.memory = nil
+			e.memoryChanged()
+		}
+	case KeyMR:
+		e.MemoryRecall(0)
+	case KeyMS:
+		if value, ok := e.memoryValue(); ok {
+			slot := MemorySlot{Name: e.nextMemoryName(), Value: value}
+			e.memory = append([]MemorySlot{slot}, e.memory...)
+			e.replace = e.entry != ""
+			e.memoryChanged()
+		}
+	case KeyMAdd:
+		e.MemoryAdd(0)
+	case KeyMSub:
+		e.MemorySubtract(0)
+	default:
+		return false
+	}
+	return true
+}
+
+// updateMemory 用 op 把当前值合并到第 i 个存储单元，没有存储单元时新建一个
+func (e *Engine) updateMemory(i int, op func(x, y Decimal) Decimal) {
+	value, ok := e.memoryValue()
+	if !ok {
+		return
+	}
+	if len(e.memory) == 0 && i == 0 {
+		e.memory = []MemorySlot{{Name: e.nextMemoryName()}}
+	}
+	if i < 0 || i >= len(e.memory) {
+		return
+	}
+	e.memory[i].Value = op(e.memory[i].Value, value)
+	e.replace = e.entry != ""
+	e.memoryChanged()
+}
+
+// memoryValue 返回要存入存储器的当前值，出错时没有可存储的值
+func (e *Engine) memoryValue() (Decimal, bool) {
+	if e.evaluated && e.err != nil {
+		return Decimal{}, false
+	}
+	return e.currentValue(), true
+}
+
+// nextMemoryName 返回未被使用的最小编号名字：M1、M2……
+func (e *Engine) nextMemoryName() string {
+	used := make(map[string]bool, len(e.memory))
+	for _, m := range e.memory {
+		used[m.Name] = true
+	}
+	for n := 1; ; n++ {
+		if name := fmt.Sprintf("M%d", n); !used[name] {
+			return name
+		}
+	}
+}
+
+func (e *Engine) memoryChanged() {
+	if e.onMemory != nil {
+		e.onMemory(e.Memory())
+	}
+}
+
+// recall 把数值作为正在输入的数字，继续输入数字时替换它
+func (e *Engine) recall(d Decimal) {
+	if e.evaluated {
+		e.startNew()
+	}
+	e.setEntry(e.entryText(d.String()))
+	e.replace = true
+}
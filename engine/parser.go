@@ -0,0 +1,274 @@
+package engine
+
+import (
+	"fmt"
+)
+
+// Node 表达式语法树节点
+type Node interface {
+	// Pos 返回节点在输入中的起始字符列
+	Pos() int
+}
+
+// Number 数字字面量
+type Number struct {
+	Text   string
+	Column int
+}
+
+// Unary 一元运算（取负、取正、按位取反）
+type Unary struct {
+	Op     string
+	X      Node
+	Column int
+}
+
+// Const 数学常量（π、e）或变量
+type Const struct {
+	Name   string
+	Value  Decimal
+	Column int
+}
+
+// Call 函数调用，如 sin(30)
+type Call struct {
+	Func   string
+	Arg    Node
+	Column int
+	fn     mathFunc
+}
+
+// Postfix 后缀运算（百分号、阶乘、平方）
+type Postfix struct {
+	Op string
+	X  Node
+}
+
+// Binary 二元运算
+type Binary struct {
+	Op     string
+	Left   Node
+	Right  Node
+	Column int
+}
+
+func (n *Number) Pos() int  { return n.Column }
+func (n *Const) Pos() int   { return n.Column }
+func (n *Call) Pos() int    { return n.Column }
+func (n *Unary) Pos() int   { return n.Column }
+func (n *Postfix) Pos() int { return n.X.Pos() }
+func (n *Binary) Pos() int  { return n.Left.Pos() }
+
+// 运算符优先级
+const (
+	precLowest  = iota
+	precOr      // OR NOR
+	precXor     // XOR
+	precAnd     // AND NAND
+	precShift   // << >> ROL ROR
+	precRate    // Δ% MU MG，低于加减：100 + 10% MU 5 = (100 + 10%) MU 5
+	precSum     // + -
+	precProduct // × ÷ MOD
+	precPrefix  // -x、NOT x、sin x
+	precPower   // x^y、x ʸ√ y，右结合
+	precPostfix // x%、n!、x²
+)
+
+var precedences = map[string]int{
+	"+":    precSum,
+	"-":    precSum,
+	"×":    precProduct,
+	"÷":    precProduct,
+	"MOD":  precProduct,
+	"Δ%":   precRate,
+	"MU":   precRate,
+	"MG":   precRate,
+	"AND":  precAnd,
+	"NAND": precAnd,
+	"XOR":  precXor,
+	"OR":   precOr,
+	"NOR":  precOr,
+	"<<":   precShift,
+	">>":   precShift,
+	"ROL":  precShift,
+	"ROR":  precShift,
+	"^":    precPower,
+	"ʸ√":   precPower,
+}
+
+// parser 基于 Pratt 算法的表达式解析器
+type parser struct {
+	tokens []token
+	pos    int
+	vars   map[string]Decimal // 可用的变量，为 nil 时表达式中只能使用常量和函数
+}
+
+// Parse 解析表达式，返回语法树
+func Parse(input string) (Node, error) {
+	return parse(input, nil)
+}
+
+// parse 解析表达式，变量在解析时替换为它的值
+func parse(input string, vars map[string]Decimal) (Node, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, vars: vars}
+	if p.peek().kind == tokEOF {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty expression"}
+	}
+	node, err := p.parseExpr(precLowest)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// startsOperand 判断词法单元能否开始一个新的操作数，用于隐式乘法
+func startsOperand(tok token) bool {
+	return tok.kind == tokNumber || tok.kind == tokLParen || tok.kind == tokIdent
+}
+
+func (p *parser) parseExpr(minPrec int) (Node, error) {
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokPostfix:
+			if precPostfix <= minPrec {
+				return left, nil
+			}
+			p.next()
+			left = &Postfix{Op: tok.text, X: left}
+		case tok.kind == tokOperator:
+			prec := precedences[tok.text]
+			if prec <= minPrec {
+				return left, nil
+			}
+			p.next()
+			if prec == precPower {
+				// 幂运算右结合：2^3^2 = 2^(3^2)，且指数可以带负号
+				prec = precPrefix
+			}
+			right, err := p.parseExpr(prec)
+			if err != nil {
+				return nil, err
+			}
+			left = &Binary{Op: tok.text, Left: left, Right: right, Column: tok.pos}
+		case startsOperand(tok):
+			// 隐式乘法：2(3+4)、(1+2)(3+4)、(1+2)3、2π、2sin(30)
+			if precProduct <= minPrec {
+				return left, nil
+			}
+			if tok.kind == tokNumber && !p.previousIs(tokRParen, tokPostfix, tokIdent) {
+				return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected number %q", tok.text)}
+			}
+			right, err := p.parseExpr(precProduct)
+			if err != nil {
+				return nil, err
+			}
+			left = &Binary{Op: "×", Left: left, Right: right, Column: tok.pos}
+		default:
+			return left, nil
+		}
+	}
+}
+
+// previousIs 判断上一个词法单元是否为指定类型之一
+func (p *parser) previousIs(kinds ...tokenKind) bool {
+	if p.pos == 0 {
+		return false
+	}
+	prev := p.tokens[p.pos-1].kind
+	for _, k := range kinds {
+		if prev == k {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parsePrefix() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		if _, err := parseNumber(tok.text); err != nil {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		return &Number{Text: tok.text, Column: tok.pos}, nil
+	case tokOperator:
+		if tok.text != "-" && tok.text != "+" && tok.text != "NOT" {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected operator %q", tok.text)}
+		}
+		x, err := p.parseExpr(precPrefix)
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: tok.text, X: x, Column: tok.pos}, nil
+	case tokIdent:
+		if value, ok := lookupConstant(tok.text); ok {
+			return &Const{Name: tok.text, Value: value, Column: tok.pos}, nil
+		}
+		if value, ok := p.vars[tok.text]; ok {
+			return &Const{Name: tok.text, Value: value, Column: tok.pos}, nil
+		}
+		fn, ok := lookupFunction(tok.text)
+		if !ok {
+			// 后面没有参数时可能是变量名，如没有提供变量时的 ans
+			msg := "unknown function %q"
+			switch {
+			case startsOperand(p.peek()):
+			case p.vars != nil:
+				msg = "unknown variable %q"
+			default:
+				msg = "unknown variable or function %q"
+			}
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf(msg, tok.text)}
+		}
+		var arg Node
+		var err error
+		if p.peek().kind == tokLParen {
+			// 带括号的参数只取括号内的部分，后面的后缀运算和乘方作用于函数值：sin(30)² = (sin 30)²
+			arg, err = p.parsePrefix()
+		} else {
+			arg, err = p.parseExpr(precPrefix)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Call{Func: tok.text, Arg: arg, Column: tok.pos, fn: fn}, nil
+	case tokLParen:
+		x, err := p.parseExpr(precLowest)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, &SyntaxError{Pos: closing.pos, Msg: "missing closing parenthesis"}
+		}
+		return x, nil
+	case tokEOF:
+		return nil, &SyntaxError{Pos: tok.pos, Msg: "unexpected end of expression"}
+	default:
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+}
@@ -0,0 +1,98 @@
+package engine
+
+// 商业计算器的百分比运算：
+//
+//	200 + 10%   = 220   加减时百分数是左操作数的百分之几
+//	200 × 10%   = 20
+//	50 ÷ 200%   = 25
+//	160 Δ% 200  = 25    从 160 到 200 的变化百分比
+//	200 MU 25   = 250   成本 200 加价 25% 后的售价
+//	200 MG 20   = 250   成本 200、毛利率 20% 时的售价
+//
+// Δ%、MU、MG 的优先级低于加减，左操作数是左边的全部算式：
+// 100 + 10% MU 5 = (100 + 10%) MU 5 = 115.5
+
+// isRateOperator 判断运算符的右操作数是否为百分率，百分率可带可不带百分号
+func isRateOperator(op string) bool {
+	return op == "Δ%" || op == "MU" || op == "MG"
+}
+
+// percentOperand 二元运算的右操作数是百分数（如 200 + 10%）时返回百分号前的部分
+func percentOperand(n *Binary) (Node, bool) {
+	p, ok := n.Right.(*Postfix)
+	if !ok || p.Op != "%" {
+		return nil, false
+	}
+	return p.X, true
+}
+
+// operands 计算二元运算的两个操作数。加减百分数时右操作数为左操作数的百分之几，
+// Δ%、MU、MG 的百分率去掉百分号；其余运算中百分数为除以 100 后的值
+func (c Config) operands(n *Binary) (left, right Decimal, err error) {
+	left, err = c.Evaluate(n.Left)
+	if err != nil {
+		return Decimal{}, Decimal{}, err
+	}
+	x, percent := percentOperand(n)
+	if !percent || n.Op != "+" && n.Op != "-" && !isRateOperator(n.Op) {
+		right, err = c.Evaluate(n.Right)
+		return left, right, err
+	}
+	right, err = c.Evaluate(x)
+	if err != nil {
+		return Decimal{}, Decimal{}, err
+	}
+	if !isRateOperator(n.Op) {
+		right, err = checkedMul(left, right.Shift(-2))
+	}
+	return left, right, err
+}
+
+// percentChange a Δ% b：从 a 到 b 的变化百分比 (b − a) ÷ a × 100
+func percentChange(c Config, a, b Decimal) (Decimal, error) {
+	d, err := b.Sub(a).Quo(a, c.precision())
+	if err != nil {
+		return Decimal{}, err
+	}
+	return d.Shift(2), nil
+}
+
+// markupFactor 加价 b% 时售价与成本之比 1 + b/100
+func markupFactor(b Decimal) Decimal {
+	return NewDecimal(1).Add(b.Shift(-2))
+}
+
+// marginFactor 毛利率为 b% 时成本与售价之比 1 − b/100
+func marginFactor(b Decimal) Decimal {
+	return NewDecimal(1).Sub(b.Shift(-2))
+}
+
+// interpret 返回表达式按百分比规则解释后的等价算式：200 + 10% 为 200 + 20，
+// 50 ÷ 200% 为 50 ÷ 2，160 Δ% 200 为 (200 − 160) ÷ 160 × 100。
+// 最外层的运算不涉及百分比时返回 nil
+func (c Config) interpret(input string) []string {
+	node, err := parse(input, c.Vars)
+	if err != nil {
+		return nil
+	}
+	n, ok := node.(*Binary)
+	if !ok {
+		return nil
+	}
+	if _, percent := percentOperand(n); !percent && !isRateOperator(n.Op) {
+		return nil
+	}
+	left, right, err := c.operands(n)
+	if err != nil {
+		return nil
+	}
+	switch n.Op {
+	case "Δ%":
+		return []string{"(", right.String(), "-", left.String(), ")", "÷", left.String(), "×", "100"}
+	case "MU":
+		return []string{left.String(), "×", markupFactor(right).String()}
+	case "MG":
+		return []string{left.String(), "÷", marginFactor(right).String()}
+	}
+	return []string{left.String(), n.Op, right.String()}
+}
@@ -0,0 +1,56 @@
+package engine
+
+import "testing"
+
+func TestPercentEval(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"200 + 10%", "220"},
+		{"200 - 10%", "180"},
+		{"200 × 10%", "20"},
+		{"50 ÷ 200%", "25"},
+		{"160 Δ% 200", "25"},
+		{"200 MU 25", "250"},
+		{"200 MU 25%", "250"},
+		{"200 MG 20", "250"},
+		// Δ%、MU、MG 的优先级低于加减
+		{"100 + 10% MU 5", "115.5"},
+		{"2 × 3 MG 20", "7.5"},
+		{"200 MU 20 + 5", "250"},
+	}
+	var c Config
+	for _, tt := range tests {
+		got, err := c.Eval(tt.input)
+		if err != nil {
+			t.Errorf("Eval(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Eval(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPercentKeyCompletes(t *testing.T) {
+	tests := []struct {
+		keys       []Key
+		display    string
+		expression string
+	}{
+		{[]Key{"2", "0", "0", KeyAdd, "1", "0", KeyPercent}, "220", "200 + 10% → 200 + 20 ="},
+		{[]Key{"5", "0", KeyDiv, "2", "0", "0", KeyPercent}, "25", "50 ÷ 200% → 50 ÷ 2 ="},
+		{[]Key{"2", "0", "0", KeyMarkup, "2", "5", KeyPercent}, "250", "200 MU 25% → 200 × 1.25 ="},
+		{[]Key{"1", "0", "0", KeyAdd, "1", "0", KeyPercent, KeyMarkup, "5", KeyEquals}, "115.5", "110 MU 5 → 110 × 1.05 ="},
+		// 百分数不是最外层运算的右操作数时等待继续输入
+		{[]Key{"1", "0", KeyPercent}, "10", "10%"},
+		{[]Key{KeyLParen, "2", "0", "0", KeyAdd, "1", "0", KeyPercent}, "10", "(200 + 10%"},
+	}
+	for _, tt := range tests {
+		s := pressAll(New(), tt.keys)
+		if s.Display != tt.display || s.Expression != tt.expression {
+			t.Errorf("%v: got %q, %q; want %q, %q", tt.keys, s.Display, s.Expression, tt.display, tt.expression)
+		}
+	}
+}
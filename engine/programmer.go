@@ -0,0 +1,391 @@
+package engine
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Mode 计算器模式
+type Mode int
+
+const (
+	ModeBasic Mode = iota
+	ModeScientific
+	ModeProgrammer
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeScientific:
+		return "sci"
+	case ModeProgrammer:
+		return "prog"
+	default:
+		return "basic"
+	}
+}
+
+// ParseMode 解析模式名，接受 String 的返回值
+func ParseMode(s string) (Mode, error) {
+	for _, m := range []Mode{ModeBasic, ModeScientific, ModeProgrammer} {
+		if strings.EqualFold(s, m.String()) {
+			return m, nil
+		}
+	}
+	return ModeBasic, fmt.Errorf("unknown mode %q", s)
+}
+
+// WordSize 程序员模式的字长和符号
+type WordSize struct {
+	Bits   int  // 8、16、32 或 64
+	Signed bool // 是否按二进制补码解释为有符号数
+}
+
+// DefaultWordSize 默认字长：64 位有符号
+var DefaultWordSize = WordSize{Bits: 64, Signed: true}
+
+func (w WordSize) String() string {
+	sign := "unsigned"
+	if w.Signed {
+		sign = "signed"
+	}
+	return fmt.Sprintf("%d-bit %s", w.bits(), sign)
+}
+
+// Next 返回 64 → 32 → 16 → 8 循环中的下一个字长
+func (w WordSize) Next() WordSize {
+	bits := w.bits() / 2
+	if bits < 8 {
+		bits = 64
+	}
+	return WordSize{Bits: bits, Signed: w.Signed}
+}
+
+func (w WordSize) bits() int {
+	switch w.Bits {
+	case 8, 16, 32, 64:
+		return w.Bits
+	}
+	return 64
+}
+
+// mask 返回 2^bits - 1
+func (w WordSize) mask() *big.Int {
+	m := new(big.Int).Lsh(big.NewInt(1), uint(w.bits()))
+	return m.Sub(m, big.NewInt(1))
+}
+
+// Wrap 把整数截断到字长，有符号时按二进制补码解释
+func (w WordSize) Wrap(n *big.Int) *big.Int {
+	v := new(big.Int).And(n, w.mask())
+	if w.Signed && v.Bit(w.bits()-1) == 1 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(w.bits())))
+	}
+	return v
+}
+
+// Pattern 返回整数在该字长下的二进制补码位模式
+func (w WordSize) Pattern(n *big.Int) uint64 {
+	return new(big.Int).And(n, w.mask()).Uint64()
+}
+
+// FromPattern 把位模式按字长解释为整数
+func (w WordSize) FromPattern(bits uint64) *big.Int {
+	return w.Wrap(new(big.Int).SetUint64(bits))
+}
+
+// basePrefixes 非十进制数字在表达式中的前缀
+var basePrefixes = map[int]string{
+	16: "0x",
+	8:  "0o",
+	2:  "0b",
+}
+
+// ValidDigit 判断数字键在指定进制下是否可用
+func ValidDigit(key Key, base int) bool {
+	if len(key) != 1 {
+		return false
+	}
+	return strings.IndexByte("0123456789ABCDEF"[:base], key[0]) >= 0
+}
+
+// Int 按进制格式化整数：十进制显示带符号的值并添加千位分隔符，
+// 其他进制显示字长内的二进制补码，每 4 位（八进制 3 位）以空格分组
+func (f Format) Int(n *big.Int, base int, w WordSize) string {
+	if base == 10 {
+		return f.entry(w.Wrap(n).String())
+	}
+	digits := strings.ToUpper(new(big.Int).And(n, w.mask()).Text(base))
+	return groupDigits(digits, base)
+}
+
+// groupDigits 从右向左按组插入空格
+func groupDigits(digits string, base int) string {
+	size := 4
+	if base == 8 {
+		size = 3
+	}
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%size == 0 {
+			b.WriteString(" ")
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// parseInt 解析带 0x/0o/0b 前缀的整数文字
+func parseInt(text string) (*big.Int, bool) {
+	return new(big.Int).SetString(text, 0)
+}
+
+// parseNumber 解析表达式中的数字，支持十进制小数和带前缀的整数
+func parseNumber(text string) (Decimal, error) {
+	if len(text) > 2 && text[0] == '0' && strings.ContainsRune("xXoObB", rune(text[1])) {
+		n, ok := parseInt(text)
+		if !ok {
+			return Decimal{}, fmt.Errorf("invalid number %q", text)
+		}
+		return NewDecimalFromBigInt(n), nil
+	}
+	return ParseDecimal(text)
+}
+
+// bitwise 按位运算、移位、循环移位和取模，操作数必须为整数
+func (c Config) bitwise(op string, x, y Decimal) (Decimal, error) {
+	if !x.IsInt() || !y.IsInt() {
+		return Decimal{}, ErrDomain
+	}
+	w := c.word()
+	a, b := x.BigInt(), y.BigInt()
+	r := new(big.Int)
+	switch op {
+	case "AND":
+		r.And(a, b)
+	case "OR":
+		r.Or(a, b)
+	case "XOR":
+		r.Xor(a, b)
+	case "NAND":
+		r.Not(r.And(a, b))
+	case "NOR":
+		r.Not(r.Or(a, b))
+	case "MOD":
+		if b.Sign() == 0 {
+			return Decimal{}, ErrDivideByZero
+		}
+		r.Rem(a, b)
+	case "<<", ">>", "ROL", "ROR":
+		if b.Sign() < 0 || b.Cmp(big.NewInt(int64(w.bits()))) > 0 {
+			return Decimal{}, ErrDomain
+		}
+		n := uint(b.Uint64())
+		switch op {
+		case "<<":
+			r.Lsh(a, n)
+		case ">>":
+			r.Rsh(a, n)
+		default:
+			r.SetUint64(rotate(w.Pattern(a), n, w.bits(), op == "ROL"))
+		}
+	default:
+		return Decimal{}, fmt.Errorf("unknown operator %q", op)
+	}
+	if op == "NAND" || op == "NOR" || op == "ROL" || op == "ROR" {
+		r = w.Wrap(r)
+	}
+	return NewDecimalFromBigInt(r), nil
+}
+
+// not 按位取反
+func (c Config) not(x Decimal) (Decimal, error) {
+	if !x.IsInt() {
+		return Decimal{}, ErrDomain
+	}
+	return NewDecimalFromBigInt(c.word().Wrap(new(big.Int).Not(x.BigInt()))), nil
+}
+
+// rotate 在 bits 位内循环移位
+func rotate(v uint64, n uint, bits int, left bool) uint64 {
+	n %= uint(bits)
+	if !left {
+		n = uint(bits) - n
+	}
+	mask := uint64(1)<<uint(bits) - 1
+	if bits == 64 {
+		mask = ^uint64(0)
+	}
+	return (v<<n | v>>(uint(bits)-n)) & mask
+}
+
+func (c Config) word() WordSize {
+	if c.Word.Bits == 0 {
+		return DefaultWordSize
+	}
+	return c.Word
+}
+
+// SetMode 切换计算器模式，当前显示的值会带入新模式
+func (e *Engine) SetMode(mode Mode) {
+	if mode == e.mode {
+		return
+	}
+	value := e.currentValue()
+	wasProgrammer := e.mode == ModeProgrammer
+	e.mode = mode
+	e.config.Integer = mode == ModeProgrammer
+	if wasProgrammer == (mode == ModeProgrammer) {
+		return
+	}
+
+	// 进入或离开程序员模式时数字的含义不同，重新开始表达式，之前的操作不能再撤销
+	e.reset()
+	e.clearUndo()
+	if mode == ModeProgrammer {
+		value = NewDecimalFromBigInt(e.config.Word.Wrap(value.BigInt()))
+	}
+	if !value.IsZero() {
+		e.setEntry(e.entryText(value.String()))
+	}
+}
+
+// base 返回当前的输入进制，只有程序员模式可以使用非十进制
+func (e *Engine) base() int {
+	if e.mode != ModeProgrammer {
+		return 10
+	}
+	return e.radix
+}
+
+// setBase 切换进制，正在输入的数字和结果按新进制重新显示
+func (e *Engine) setBase(base int) {
+	if e.mode != ModeProgrammer || base == e.radix {
+		return
+	}
+	value := e.currentValue()
+	entry := e.entry
+	e.radix = base
+	switch {
+	case entry != "":
+		e.setEntry(e.entryText(value.String()))
+	case e.evaluated && e.err == nil:
+		e.display = e.formatValue(e.ans)
+	default:
+		e.display = e.formatValue(value)
+	}
+}
+
+// setWordSize 切换字长或符号，当前值按新字长截断
+func (e *Engine) setWordSize(w WordSize) {
+	if e.mode != ModeProgrammer {
+		e.config.Word = w
+		return
+	}
+	value := e.currentValue()
+	e.config.Word = w
+	wrapped := NewDecimalFromBigInt(w.Wrap(value.BigInt()))
+	switch {
+	case e.entry != "":
+		e.setEntry(e.entryText(wrapped.String()))
+	case e.evaluated && e.err == nil:
+		e.ans = wrapped
+		e.display = e.formatValue(wrapped)
+	default:
+		e.display = e.formatValue(wrapped)
+	}
+}
+
+// fitsWord 判断正在输入的数字是否在字长范围内
+func (e *Engine) fitsWord(entry string) bool {
+	n, ok := parseInt(basePrefixes[e.base()] + entry)
+	if !ok {
+		return false
+	}
+	w := e.config.Word
+	if e.base() != 10 {
+		// 非十进制输入的是位模式，不能超过字长的位数
+		return n.BitLen() <= w.bits()
+	}
+	return w.Wrap(n).Cmp(n) == 0 || !w.Signed && n.Sign() >= 0 && n.Cmp(w.mask()) <= 0
+}
+
+// entryText 把十进制数字文字转换为当前进制下可继续编辑的数字
+func (e *Engine) entryText(token string) string {
+	if e.mode != ModeProgrammer {
+		return token
+	}
+	value, err := parseNumber(token)
+	if err != nil {
+		return token
+	}
+	n := value.BigInt()
+	if e.base() == 10 {
+		return e.config.Word.Wrap(n).String()
+	}
+	return strings.ToUpper(new(big.Int).And(n, e.config.Word.mask()).Text(e.base()))
+}
+
+// numberToken 把数值转换为表达式中的词法单元
+func (e *Engine) numberToken(d Decimal) string {
+	if e.mode != ModeProgrammer {
+		return d.String()
+	}
+	return basePrefixes[e.base()] + e.entryText(d.String())
+}
+
+// currentValue 返回显示中的数值：正在输入的数字、上一次结果或表达式中最后一个数字
+func (e *Engine) currentValue() Decimal {
+	if e.entry != "" {
+		v, _ := parseNumber(e.entryToken())
+		return v
+	}
+	if e.evaluated {
+		return e.ans
+	}
+	for i := len(e.tokens) - 1; i >= 0; i-- {
+		if v, err := parseNumber(e.tokens[i]); err == nil {
+			return v
+		}
+	}
+	return Decimal{}
+}
+
+// Readout 返回程序员模式下状态 s 的当前值在指定进制下的显示文字
+func (f Format) Readout(s State, base int) string {
+	return f.Int(s.Word.FromPattern(s.Bits), base, s.Word)
+}
+
+// ToggleBit 翻转当前值的第 bit 位（0 为最低位）并返回新的状态，
+// 超出字长的位和非程序员模式下的调用被忽略
+func (e *Engine) ToggleBit(bit int) State {
+	e.track(func() { e.toggleBit(bit) })
+	return e.State()
+}
+
+func (e *Engine) toggleBit(bit int) {
+	if e.mode != ModeProgrammer || bit < 0 || bit >= e.config.Word.bits() {
+		return
+	}
+	if e.evaluated {
+		e.startNew()
+		e.entry = e.entryText(e.ans.String())
+	} else if e.entry == "" && e.endsOperand() {
+		if !isNumber(e.last()) {
+			// 括号或常量结尾时没有可修改的数字
+			return
+		}
+		// 修改表达式中最后一个数字
+		last := e.tokens[len(e.tokens)-1]
+		e.tokens = e.tokens[:len(e.tokens)-1]
+		e.entry = e.entryText(last)
+	}
+	var bits uint64
+	if e.entry != "" {
+		value, _ := parseNumber(e.entryToken())
+		bits = e.config.Word.Pattern(value.BigInt())
+	}
+	bits ^= 1 << uint(bit)
+	e.setEntry(e.entryText(e.config.Word.FromPattern(bits).String()))
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWordSizeWrap(t *testing.T) {
+	tests := []struct {
+		word WordSize
+		n    string
+		want string
+	}{
+		{WordSize{Bits: 8, Signed: true}, "127", "127"},
+		{WordSize{Bits: 8, Signed: true}, "128", "-128"},
+		{WordSize{Bits: 8, Signed: true}, "-129", "127"},
+		{WordSize{Bits: 8}, "-1", "255"},
+		{WordSize{Bits: 8}, "256", "0"},
+		{WordSize{Bits: 16, Signed: true}, "32768", "-32768"},
+		{WordSize{Bits: 16}, "65535", "65535"},
+		{WordSize{Bits: 32, Signed: true}, "4294967295", "-1"},
+		{WordSize{Bits: 32}, "4294967296", "0"},
+		{WordSize{Bits: 64, Signed: true}, "9223372036854775808", "-9223372036854775808"},
+		{WordSize{Bits: 64}, "-1", "18446744073709551615"},
+	}
+	for _, tt := range tests {
+		n, _ := new(big.Int).SetString(tt.n, 10)
+		if got := tt.word.Wrap(n).String(); got != tt.want {
+			t.Errorf("%v Wrap(%s) = %s, want %s", tt.word, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestProgrammerPress(t *testing.T) {
+	tests := []struct {
+		keys    string
+		display string
+	}{
+		// 64 → 32 → 16 → 8 位
+		{"Word Word Word 1 2 7 + 1 =", "-128"},
+		{"Word Word Word Sign 2 5 5 + 1 =", "0"},
+		{"Word Word Word Sign 2 0 0 Sign", "-56"},
+		{"3 0 0 Word Word Word", "44"},
+		{"1 0 0 0 0 0 Word Word", "-31,072"},
+		{"Sign 1 8 4 4 6 7 4 4 0 7 3 7 0 9 5 5 1 6 1 5", "18,446,744,073,709,551,615"},
+		// 切换进制时正在输入的数字和结果按新进制显示
+		{"HEX F F DEC", "255"},
+		{"HEX F F BIN", "1111 1111"},
+		{"2 5 5 HEX", "FF"},
+		{"8 OCT", "10"},
+		{"HEX F F + 1 = DEC", "256"},
+		{"HEX F F ±", "FFFF FFFF FFFF FF01"},
+		// 超出字长的数字不能输入
+		{"Word Word Word 1 2 8", "12"},
+		{"Word Word Word HEX 1 F F", "1F"},
+		{"9 2 2 3 3 7 2 0 3 6 8 5 4 7 7 5 8 0 8", "922,337,203,685,477,580"},
+		// 移位和循环移位
+		{"1 << 4 =", "16"},
+		{"2 5 6 >> 4 =", "16"},
+		{"Word Word Word Sign 1 ROR 1 =", "128"},
+		{"Word Word Word Sign 1 2 8 ROL 1 =", "1"},
+		{"Word Word Word 1 2 7 ± - 1 =", "-128"},
+		{"Word Word Word 1 2 7 ± - 2 =", "127"},
+		// 按位运算和取反
+		{"HEX F 0 AND 3 C =", "30"},
+		{"HEX F 0 OR 0 F =", "FF"},
+		{"HEX F 0 XOR F F =", "F"},
+		{"0 NOT =", "-1"},
+		{"Word Word Word Sign 0 NOT =", "255"},
+		{"1 7 MOD 5 =", "2"},
+		// 整数运算
+		{"7 ÷ 2 =", "3"},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.SetMode(ModeProgrammer)
+		s := pressAll(e, keys(tt.keys))
+		if s.Err != nil || s.Display != tt.display {
+			t.Errorf("%s: got %q, %v; want %q", tt.keys, s.Display, s.Err, tt.display)
+		}
+	}
+}
+
+func TestProgrammerExpression(t *testing.T) {
+	tests := []struct {
+		keys       string
+		expression string
+	}{
+		{"Sign 1 8 4 4 6 7 4 4 0 7 3 7 0 9 5 5 1 6 1 5 AND 3 =", "18,446,744,073,709,551,615 AND 3 ="},
+		{"HEX F F AND 3 0 =", "0xFF AND 0x30 ="},
+		{"1 2 3 4 5 << 2 =", "12,345 << 2 ="},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.SetMode(ModeProgrammer)
+		if s := pressAll(e, keys(tt.keys)); s.Expression != tt.expression {
+			t.Errorf("%s: got %q, want %q", tt.keys, s.Expression, tt.expression)
+		}
+	}
+}
+
+func TestToggleBit(t *testing.T) {
+	tests := []struct {
+		keys       string
+		bits       []int
+		display    string
+		expression string
+	}{
+		{"", []int{63}, "-9,223,372,036,854,775,808", "-9,223,372,036,854,775,808"},
+		{"Sign", []int{63}, "9,223,372,036,854,775,808", "9,223,372,036,854,775,808"},
+		{"4", []int{0}, "5", "5"},
+		{"7", []int{1, 1}, "7", "7"},
+		{"HEX", []int{4, 0}, "11", "0x11"},
+		{"Word Word Word", []int{7}, "-128", "-128"},
+		// 超出字长的位不变
+		{"Word Word Word 5", []int{8}, "5", "5"},
+		// 修改结果时开始新的表达式，修改运算符前的数字
+		{"2 + 2 =", []int{0}, "5", "5"},
+		{"1 + 2", []int{2}, "6", "1 + 6"},
+		{"1 +", []int{0}, "1", "1 + 1"},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.SetMode(ModeProgrammer)
+		s := pressAll(e, keys(tt.keys))
+		for _, bit := range tt.bits {
+			s = e.ToggleBit(bit)
+		}
+		if s.Display != tt.display || s.Expression != tt.expression {
+			t.Errorf("%s, toggle %v: got %q, %q; want %q, %q", tt.keys, tt.bits, s.Display, s.Expression, tt.display, tt.expression)
+		}
+	}
+
+	// 其他模式中没有位面板，按位切换不起作用
+	e := New()
+	if s := pressAll(e, keys("4")); e.ToggleBit(0) != s {
+		t.Error("ToggleBit changed the state outside programmer mode")
+	}
+}
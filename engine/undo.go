@@ -0,0 +1,115 @@
+package engine
+
+import "slices"
+
+// UndoLimit 撤销栈最多保留的步数
+const UndoLimit = 100
+
+// snapshot 撤销和重做恢复的计算状态，不包括计算历史和模式
+type snapshot struct {
+	tokens     []string
+	entry      string
+	display    string
+	expression string
+	ans        Decimal
+	evaluated  bool
+	err        error
+	repeat     []string
+	angle      AngleMode
+	word       WordSize
+	radix      int
+	memory     []MemorySlot
+	replace    bool
+}
+
+// Undo 恢复上一次操作之前的状态，包括全部清除和存储器的修改
+func (e *Engine) Undo() State {
+	if len(e.undo) > 0 {
+		e.redo = append(e.redo, e.snapshot())
+		e.restore(e.undo[len(e.undo)-1])
+		e.undo = e.undo[:len(e.undo)-1]
+	}
+	return e.State()
+}
+
+// Redo 重新执行最近撤销的操作
+func (e *Engine) Redo() State {
+	if len(e.redo) > 0 {
+		e.undo = append(e.undo, e.snapshot())
+		e.restore(e.redo[len(e.redo)-1])
+		e.redo = e.redo[:len(e.redo)-1]
+	}
+	return e.State()
+}
+
+// track 执行一次操作，状态改变时记录到撤销栈并清空重做栈
+func (e *Engine) track(f func()) {
+	if e.tracking {
+		f()
+		return
+	}
+	e.tracking = true
+	before, state := e.snapshot(), e.State()
+	f()
+	e.tracking = false
+	// 只改变内部表示而显示不变的操作（如没有可闭合括号时的右括号）不记录
+	if e.State() == state && equalMemory(before.memory, e.memory) {
+		return
+	}
+	e.undo = append(e.undo, before)
+	if len(e.undo) > UndoLimit {
+		e.undo = slices.Delete(e.undo, 0, len(e.undo)-UndoLimit)
+	}
+	e.redo = nil
+}
+
+// clearUndo 清空撤销和重做栈，如切换到数字含义不同的模式之后
+func (e *Engine) clearUndo() {
+	e.undo = nil
+	e.redo = nil
+}
+
+func (e *Engine) snapshot() snapshot {
+	return snapshot{
+		tokens:     slices.Clone(e.tokens),
+		entry:      e.entry,
+		display:    e.display,
+		expression: e.expression,
+		ans:        e.ans,
+		evaluated:  e.evaluated,
+		err:        e.err,
+		repeat:     slices.Clone(e.repeat),
+		angle:      e.config.Angle,
+		word:       e.config.Word,
+		radix:      e.radix,
+		memory:     slices.Clone(e.memory),
+		replace:    e.replace,
+	}
+}
+
+// restore 恢复快照，存储器有变化时通知保存
+func (e *Engine) restore(s snapshot) {
+	memoryChanged := !equalMemory(e.memory, s.memory)
+	e.tokens = slices.Clone(s.tokens)
+	e.entry = s.entry
+	e.display = s.display
+	e.expression = s.expression
+	e.ans = s.ans
+	e.evaluated = s.evaluated
+	e.err = s.err
+	e.repeat = slices.Clone(s.repeat)
+	e.config.Angle = s.angle
+	e.config.Word = s.word
+	e.radix = s.radix
+	e.memory = slices.Clone(s.memory)
+	e.replace = s.replace
+	if memoryChanged {
+		e.memoryChanged()
+	}
+}
+
+func equalMemory(a, b []MemorySlot) bool {
+	return slices.EqualFunc(a, b, func(x, y MemorySlot) bool {
+		return x.Name == y.Name && x.Value.Cmp(y.Value) == 0
+	})
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		keys       string
+		display    string
+		expression string
+	}{
+		{"1 2 Undo", "1", "1"},
+		{"1 2 Undo Undo", "0", ""},
+		{"1 2 Undo Redo", "12", "12"},
+		{"2 + 3 = Undo", "3", "2 + 3"},
+		{"2 + 3 = Undo Redo", "5", "2 + 3 ="},
+		// 全部清除可以撤销
+		{"2 + 3 AC Undo", "3", "2 + 3"},
+		{"2 + 3 = AC Undo", "5", "2 + 3 ="},
+		{"1 ÷ 0 = Undo", "0", "1 ÷ 0"},
+		// 撤销后按其他键清空重做栈
+		{"1 2 Undo 5 Redo", "15", "15"},
+		{"1 2 Undo Undo Redo 5 Redo", "15", "15"},
+		// 没有可撤销或重做的操作时不变
+		{"Undo Redo", "0", ""},
+		{"7 Redo", "7", "7"},
+	}
+	for _, tt := range tests {
+		s := pressAll(New(), keys(tt.keys))
+		if s.Display != tt.display || s.Expression != tt.expression {
+			t.Errorf("%s: got %q, %q; want %q, %q", tt.keys, s.Display, s.Expression, tt.display, tt.expression)
+		}
+	}
+}
+
+func TestUndoMemory(t *testing.T) {
+	e := New()
+	var saved int
+	e.OnMemory(func([]MemorySlot) { saved++ })
+
+	pressAll(e, keys("5 MS 3 M+"))
+	if m := e.Memory(); len(m) != 1 || m[0].Value.String() != "8" {
+		t.Fatalf("memory after 5 MS 3 M+ = %v", m)
+	}
+	e.Undo()
+	if m := e.Memory(); len(m) != 1 || m[0].Value.String() != "5" {
+		t.Errorf("memory after undoing M+ = %v, want [5]", m)
+	}
+	pressAll(e, keys("Undo Undo"))
+	if m := e.Memory(); len(m) != 0 {
+		t.Errorf("memory after undoing MS = %v, want none", m)
+	}
+	e.Redo()
+	if m := e.Memory(); len(m) != 1 || m[0].Value.String() != "5" {
+		t.Errorf("memory after redoing MS = %v, want [5]", m)
+	}
+	// 撤销和重做改变存储器时通知保存
+	if saved != 5 {
+		t.Errorf("memory saved %d times, want 5", saved)
+	}
+
+	pressAll(e, keys("MC Undo"))
+	if m := e.Memory(); len(m) != 1 {
+		t.Errorf("memory after undoing MC = %v, want [5]", m)
+	}
+}
+
+func TestUndoLimit(t *testing.T) {
+	e := New()
+	for i := range UndoLimit + 20 {
+		e.Press(Key(fmt.Sprint(i%9 + 1)))
+		e.Press(KeyClear)
+	}
+	undone := 0
+	for ; undone < 2*UndoLimit; undone++ {
+		before := e.State()
+		if e.Undo() == before {
+			break
+		}
+	}
+	if undone != UndoLimit {
+		t.Errorf("undid %d steps, want %d", undone, UndoLimit)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"image"
+
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"gocalc/engine"
+)
+
+// errorTip 出错时鼠标悬停在结果上显示的错误说明
+type errorTip struct {
+	hovered bool
+}
+
+// errorMessage 显示区代替结果显示的简短错误信息
+func (c *Calculator) errorMessage(err error) string {
+	switch engine.KindOf(err) {
+	case engine.KindSyntax:
+		return c.tr("Invalid expression")
+	case engine.KindDivideByZero:
+		return c.tr("Cannot divide by zero")
+	case engine.KindOverflow:
+		return c.tr("Result too large")
+	case engine.KindDomain:
+		return c.tr("Invalid input")
+	case engine.KindPrecisionLoss:
+		return c.tr("Precision lost")
+	default:
+		return c.tr("Error")
+	}
+}
+
+// errorDetail 错误说明，解释出错的原因和如何继续
+func (c *Calculator) errorDetail(err error) string {
+	var detail string
+	switch engine.KindOf(err) {
+	case engine.KindSyntax:
+		detail = c.tr("The expression could not be understood.")
+	case engine.KindDivideByZero:
+		detail = c.tr("Division by zero has no result.")
+	case engine.KindOverflow:
+		detail = c.tr("The result is too large to be represented.")
+	case engine.KindDomain:
+		detail = c.tr("The value is outside the domain of the function, e.g. the logarithm or square root of a negative number.")
+	case engine.KindPrecisionLoss:
+		detail = c.tr("The value is too large or too small for the function to give a meaningful result.")
+	default:
+		detail = err.Error()
+	}
+	return c.tr("%s Press AC, CE or ⌫, or enter a new number to continue.", detail)
+}
+
+// withErrorTip 出错时鼠标悬停在结果上，在结果上方显示错误说明。
+// 悬停区域延迟到最后绘制并设为穿透，不影响显示区的右键菜单
+func (c *Calculator) withErrorTip(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: &c.errorTip, Kinds: pointer.Enter | pointer.Leave})
+		if !ok {
+			break
+		}
+		if e, ok := ev.(pointer.Event); ok {
+			c.errorTip.hovered = e.Kind == pointer.Enter
+			c.invalidate()
+		}
+	}
+
+	dims := w(gtx)
+	macro := op.Record(gtx.Ops)
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	area := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
+	event.Op(gtx.Ops, &c.errorTip)
+	area.Pop()
+	pass.Pop()
+	if c.errorTip.hovered && c.state.Err != nil {
+		c.layoutErrorTip(gtx, dims.Size)
+	}
+	op.Defer(gtx.Ops, macro.Stop())
+	return dims
+}
+
+// layoutErrorTip 在结果上方右对齐绘制错误说明
+func (c *Calculator) layoutErrorTip(gtx layout.Context, size image.Point) {
+	m := op.Record(gtx.Ops)
+	gtx.Constraints = layout.Constraints{Max: image.Pt(size.X, gtx.Constraints.Max.Y)}
+	dims := layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			rect := clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, gtx.Dp(unit.Dp(6)))
+			paint.FillShape(gtx.Ops, c.palette.Surface, rect.Op(gtx.Ops))
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(c.theme, c.errorDetail(c.state.Err))
+				label.Color = c.palette.Text
+				label.Alignment = text.Start
+				return label.Layout(gtx)
+			})
+		}),
+	)
+	call := m.Stop()
+
+	gap := gtx.Dp(unit.Dp(4))
+	defer op.Offset(image.Pt(size.X-dims.Size.X, -dims.Size.Y-gap)).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
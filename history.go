@@ -0,0 +1,147 @@
+package main
+
+import (
+	"image"
+	"log"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"gocalc/engine"
+)
+
+// historyRow 历史列表中一条记录的点击区域
+type historyRow struct {
+	expression widget.Clickable // 点击表达式：重新编辑该表达式
+	result     widget.Clickable // 点击结果：把结果带入显示
+}
+
+// layoutHistory 历史面板，最新的记录在最上面
+func (c *Calculator) layoutHistory(gtx layout.Context) layout.Dimensions {
+	history := c.engine.History()
+	if len(c.historyRows) < len(history) {
+		c.historyRows = append(c.historyRows, make([]historyRow, len(history)-len(c.historyRows))...)
+	}
+
+	// 面板背景
+	rect := clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Max}, gtx.Dp(unit.Dp(10)))
+	paint.FillShape(gtx.Ops, c.palette.Surface, rect.Op(gtx.Ops))
+
+	return layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			// 标题和清除按钮
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return c.layoutPanelHeader(gtx, c.tr("History"), &c.clearHistory)
+				})
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				if len(history) == 0 {
+					label := material.Body2(c.theme, c.tr("No history yet"))
+					label.Color = c.palette.Secondary
+					label.Alignment = text.Middle
+					return layout.Center.Layout(gtx, label.Layout)
+				}
+				return material.List(c.theme, &c.historyList).Layout(gtx, len(history), func(gtx layout.Context, i int) layout.Dimensions {
+					index := len(history) - 1 - i
+					return c.layoutHistoryEntry(gtx, &c.historyRows[index], history[index])
+				})
+			}),
+		)
+	})
+}
+
+// layoutHistoryEntry 一条历史记录：表达式（小字、灰色）和结果（大字、白色），右对齐
+func (c *Calculator) layoutHistoryEntry(gtx layout.Context, row *historyRow, h engine.HistoryEntry) layout.Dimensions {
+	return layout.Inset{Bottom: unit.Dp(15)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Flex{
+			Axis:      layout.Vertical,
+			Alignment: layout.End,
+		}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return row.expression.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					label := material.Caption(c.theme, h.Expression+" =")
+					label.Color = c.palette.Secondary
+					label.Alignment = text.End
+					label.TextSize = unit.Sp(14)
+					return label.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return row.result.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					label := material.H5(c.theme, h.Display)
+					label.Color = c.palette.Text
+					label.Alignment = text.End
+					label.MaxLines = 1
+					label.TextSize = c.fitTextSize(gtx, label, unit.Sp(24), unit.Sp(12))
+					return label.Layout(gtx)
+				})
+			}),
+		)
+	})
+}
+
+// handleHistoryEvents 处理历史按钮和历史记录的点击
+func (c *Calculator) handleHistoryEvents(gtx layout.Context) {
+	if c.historyBtn.Clicked(gtx) {
+		c.togglePanel(panelHistory)
+	}
+	if c.clearHistory.Clicked(gtx) {
+		c.engine.ClearHistory()
+		if c.historyStore != nil {
+			if err := c.historyStore.Clear(); err != nil {
+				log.Printf("clear history: %v", err)
+			}
+		}
+		c.invalidate()
+	}
+
+	history := c.engine.History()
+	for i := range c.historyRows {
+		if i >= len(history) {
+			break
+		}
+		recalled := true
+		switch {
+		case c.historyRows[i].expression.Clicked(gtx):
+			c.state = c.engine.RecallExpression(history[i])
+		case c.historyRows[i].result.Clicked(gtx):
+			c.state = c.engine.RecallResult(history[i])
+		default:
+			recalled = false
+		}
+		if recalled {
+			// 窄窗口中历史面板覆盖键盘，选择后收起
+			if !c.panelDocked(gtx) {
+				c.panel = panelNone
+			}
+			c.invalidate()
+		}
+	}
+}
+
+// loadHistory 载入保存的计算历史，之后每次计算都追加到历史文件中
+func (c *Calculator) loadHistory() {
+	store, err := newHistoryStore()
+	if err != nil {
+		log.Printf("history disabled: %v", err)
+		return
+	}
+	entries, err := store.Load()
+	if err != nil {
+		log.Printf("load history: %v", err)
+	}
+	c.engine.SetHistory(entries)
+	c.historyStore = store
+	c.engine.OnHistory(func(h engine.HistoryEntry) {
+		if err := store.Append(h); err != nil {
+			log.Printf("save history: %v", err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gocalc/engine"
+)
+
+// historyFileName 历史文件名，位于用户配置目录下的 gocalc 目录中
+const historyFileName = "history.jsonl"
+
+// historyRecord 历史文件中的一行（JSON Lines 格式，每行一个 JSON 对象，最早的在前）：
+//
+//	{"time":"2024-05-01T10:00:00+08:00","mode":"basic","expression":"1 + 2","input":"1 + 2","result":"3","display":"3"}
+//
+// time 为 RFC 3339 时间，mode 为 basic、sci 或 prog，expression 为显示用的表达式，
+// input 为可重新解析的表达式，result 为完整精度的十进制结果，display 为当时显示的结果。
+// 无法解析的行（如写入中断留下的半行）在载入时被跳过。
+type historyRecord struct {
+	Time       time.Time `json:"time"`
+	Mode       string    `json:"mode"`
+	Expression string    `json:"expression"`
+	Input      string    `json:"input"`
+	Result     string    `json:"result"`
+	Display    string    `json:"display"`
+}
+
+// historyStore 保存在磁盘上的计算历史
+type historyStore struct {
+	path  string
+	lines int // 文件当前的行数，超过上限的两倍时压缩
+}
+
+// newHistoryStore 在用户配置目录下创建历史存储
+func newHistoryStore() (*historyStore, error) {
+	path, err := configPath(historyFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &historyStore{path: path}, nil
+}
+
+// Load 读取历史记录，跳过损坏的行；文件有损坏或超出条数上限时重写为干净的文件
+func (s *historyStore) Load() ([]engine.HistoryEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []engine.HistoryEntry
+	dirty := len(data) > 0 && data[len(data)-1] != '\n'
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		h, ok := parseHistoryRecord(scanner.Bytes())
+		if !ok {
+			dirty = true
+			continue
+		}
+		entries = append(entries, h)
+	}
+	if err := scanner.Err(); err != nil {
+		// 单行过长等读取错误，保留已经读到的记录
+		dirty = true
+	}
+
+	s.lines = len(entries)
+	if dirty || len(entries) > engine.HistoryLimit {
+		entries = trimEntries(entries)
+		if err := s.rewrite(entries); err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+// Append 在文件末尾追加一条记录
+func (s *historyStore) Append(h engine.HistoryEntry) error {
+	line, err := json.Marshal(newHistoryRecord(h))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	// 整行一次写入，中断时最多留下一个会被跳过的半行
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	s.lines++
+	if s.lines > engine.HistoryLimit*2 {
+		_, err = s.Load()
+	}
+	return err
+}
+
+// Clear 删除历史文件
+func (s *historyStore) Clear() error {
+	s.lines = 0
+	err := os.Remove(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// rewrite 用给定的记录重写历史文件
+func (s *historyStore) rewrite(entries []engine.HistoryEntry) error {
+	var buf bytes.Buffer
+	for _, h := range entries {
+		line, err := json.Marshal(newHistoryRecord(h))
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := writeFileAtomic(s.path, buf.Bytes()); err != nil {
+		return err
+	}
+	s.lines = len(entries)
+	return nil
+}
+
+func newHistoryRecord(h engine.HistoryEntry) historyRecord {
+	return historyRecord{
+		Time:       h.Time,
+		Mode:       h.Mode.String(),
+		Expression: h.Expression,
+		Input:      h.Input,
+		Result:     h.Result.String(),
+		Display:    h.Display,
+	}
+}
+
+// parseHistoryRecord 解析一行历史记录，格式不正确时返回 false
+func parseHistoryRecord(line []byte) (engine.HistoryEntry, bool) {
+	var r historyRecord
+	if err := json.Unmarshal(line, &r); err != nil {
+		return engine.HistoryEntry{}, false
+	}
+	result, err := engine.ParseDecimal(r.Result)
+	if err != nil || r.Input == "" {
+		return engine.HistoryEntry{}, false
+	}
+	mode, err := engine.ParseMode(r.Mode)
+	if err != nil {
+		return engine.HistoryEntry{}, false
+	}
+	if r.Expression == "" {
+		r.Expression = r.Input
+	}
+	if r.Display == "" {
+		r.Display = engine.FormatNumber(result)
+	}
+	return engine.HistoryEntry{
+		Expression: r.Expression,
+		Input:      r.Input,
+		Result:     result,
+		Display:    r.Display,
+		Time:       r.Time,
+		Mode:       mode,
+	}, true
+}
+
+// trimEntries 只保留最近的 engine.HistoryLimit 条记录
+func trimEntries(entries []engine.HistoryEntry) []engine.HistoryEntry {
+	if len(entries) > engine.HistoryLimit {
+		return entries[len(entries)-engine.HistoryLimit:]
+	}
+	return entries
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gocalc/engine"
+)
+
+func newTestHistoryStore(t *testing.T) *historyStore {
+	t.Helper()
+	return &historyStore{path: filepath.Join(t.TempDir(), historyFileName)}
+}
+
+func testHistoryEntry(i int) engine.HistoryEntry {
+	input := fmt.Sprintf("%d + 1", i)
+	return engine.HistoryEntry{
+		Expression: input,
+		Input:      input,
+		Result:     engine.NewDecimal(int64(i + 1)),
+		Display:    fmt.Sprint(i + 1),
+		Time:       time.Date(2024, 5, 1, 10, 0, i%60, 0, time.UTC),
+		Mode:       engine.ModeScientific,
+	}
+}
+
+// historyLines 返回历史文件的行数，并检查文件以换行结尾
+func historyLines(t *testing.T, s *historyStore) int {
+	t.Helper()
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		t.Errorf("history file does not end with a newline")
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestHistoryStoreAppendLoad(t *testing.T) {
+	s := newTestHistoryStore(t)
+	if entries, err := s.Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("Load of a missing file = %v, %v", entries, err)
+	}
+	for i := range 3 {
+		if err := s.Append(testHistoryEntry(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := (&historyStore{path: s.path}).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("loaded %d entries, want 3", len(entries))
+	}
+	for i, h := range entries {
+		want := testHistoryEntry(i)
+		if h.Input != want.Input || h.Result.Cmp(want.Result) != 0 || h.Display != want.Display || !h.Time.Equal(want.Time) || h.Mode != want.Mode {
+			t.Errorf("entry %d = %+v, want %+v", i, h, want)
+		}
+	}
+}
+
+func TestHistoryStoreSkipsCorruptLines(t *testing.T) {
+	s := newTestHistoryStore(t)
+	content := `{"time":"2024-05-01T10:00:00Z","mode":"basic","expression":"1 + 2","input":"1 + 2","result":"3","display":"3"}
+not json
+{"mode":"basic","input":"","result":"1"}
+{"mode":"basic","input":"2 × 3","result":"6"}
+{"mode":"basic","input":"4 ÷ 0","result":"x"}
+{"time":"2024-05-01T10:00:00Z","mode":"basic","input":"5 + 5","res`
+	if err := os.WriteFile(s.path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Input != "1 + 2" || entries[1].Input != "2 × 3" {
+		t.Fatalf("loaded %+v, want the entries 1 + 2 and 2 × 3", entries)
+	}
+	// 缺少的表达式和显示结果使用默认值
+	if entries[1].Expression != "2 × 3" || entries[1].Display != "6" {
+		t.Errorf("defaults not filled in: %+v", entries[1])
+	}
+
+	// 损坏的文件被重写为只有有效记录的干净文件，追加的记录从新的一行开始
+	if n := historyLines(t, s); n != 2 {
+		t.Errorf("rewritten file has %d lines, want 2", n)
+	}
+	if err := s.Append(testHistoryEntry(7)); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err = s.Load(); err != nil || len(entries) != 3 {
+		t.Errorf("after append loaded %d entries, %v; want 3", len(entries), err)
+	}
+
+	// 原子重写不留下临时文件
+	files, err := os.ReadDir(filepath.Dir(s.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("history directory has %d files, want 1", len(files))
+	}
+}
+
+func TestHistoryStoreLimit(t *testing.T) {
+	s := newTestHistoryStore(t)
+	var buf bytes.Buffer
+	for i := range engine.HistoryLimit + 10 {
+		fmt.Fprintf(&buf, `{"mode":"basic","input":"%d + 1","result":"%d"}`+"\n", i, i+1)
+	}
+	if err := os.WriteFile(s.path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 超出上限的文件在载入时只保留最近的记录
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != engine.HistoryLimit || entries[0].Input != "10 + 1" {
+		t.Fatalf("loaded %d entries starting with %q, want %d starting with 10 + 1", len(entries), entries[0].Input, engine.HistoryLimit)
+	}
+	if n := historyLines(t, s); n != engine.HistoryLimit {
+		t.Errorf("file has %d lines after load, want %d", n, engine.HistoryLimit)
+	}
+
+	// 追加到上限的两倍之前不压缩，超过后压缩为上限条数
+	for i := range engine.HistoryLimit {
+		if err := s.Append(testHistoryEntry(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := historyLines(t, s); n != 2*engine.HistoryLimit {
+		t.Errorf("file has %d lines before compaction, want %d", n, 2*engine.HistoryLimit)
+	}
+	if err := s.Append(testHistoryEntry(0)); err != nil {
+		t.Fatal(err)
+	}
+	if n := historyLines(t, s); n != engine.HistoryLimit {
+		t.Errorf("file has %d lines after compaction, want %d", n, engine.HistoryLimit)
+	}
+}
+
+func TestHistoryStoreClear(t *testing.T) {
+	s := newTestHistoryStore(t)
+	if err := s.Clear(); err != nil {
+		t.Errorf("Clear of a missing file: %v", err)
+	}
+	if err := s.Append(testHistoryEntry(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := s.Load(); err != nil || len(entries) != 0 {
+		t.Errorf("Load after Clear = %v, %v", entries, err)
+	}
+}
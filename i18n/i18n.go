@@ -0,0 +1,77 @@
+// Package i18n 界面文字的翻译和按语言区域的数字分隔符，不依赖任何 GUI
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Locale 语言区域：界面文字的语言和数字的小数点、千位分隔符
+type Locale struct {
+	Tag   string // 语言标签，如 "zh-CN"、"de-DE"
+	Lang  string // 界面文字的语言：en 或 zh
+	Point rune   // 小数点
+	Group rune   // 千位分隔符
+}
+
+// catalogs 各语言的翻译，以英文原文为键；英文不需要翻译表
+var catalogs = map[string]map[string]string{
+	"zh": zh,
+}
+
+// New 根据语言标签创建语言区域，如 "de_DE.UTF-8" 或 "zh-Hans-CN"；
+// 没有翻译的语言使用英文界面，但仍使用该语言的数字分隔符
+func New(tag string) Locale {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		tag = "en"
+	}
+	lang, region, _ := strings.Cut(tag, "-")
+	l := Locale{Tag: tag, Lang: "en"}
+	if _, ok := catalogs[lang]; ok {
+		l.Lang = lang
+	}
+	l.Point, l.Group = separators(lang, region)
+	return l
+}
+
+// Detect 读取系统的语言区域：依次检查 LC_ALL、LC_MESSAGES、LANG 环境变量，
+// 都没有设置时使用操作系统的设置，仍无法识别时使用英文
+func Detect() Locale {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if tag := normalizeTag(os.Getenv(name)); tag != "" {
+			return New(tag)
+		}
+	}
+	return New(systemLocale())
+}
+
+// T 翻译界面文字，没有翻译时返回原文；有参数时按 fmt.Sprintf 格式化
+func (l Locale) T(msg string, args ...any) string {
+	if s, ok := catalogs[l.Lang][msg]; ok {
+		msg = s
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// normalizeTag 把 POSIX 形式的 "de_DE.UTF-8@euro" 转为 "de-DE"，C 和 POSIX 视为未设置
+func normalizeTag(tag string) string {
+	tag, _, _ = strings.Cut(tag, ".")
+	tag, _, _ = strings.Cut(tag, "@")
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "C" || tag == "POSIX" {
+		return ""
+	}
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,67 @@
+package i18n
+
+import "testing"
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Locale
+	}{
+		{"de-DE", Locale{Tag: "de-DE", Lang: "en", Point: ',', Group: '.'}},
+		{"de_DE.UTF-8", Locale{Tag: "de-DE", Lang: "en", Point: ',', Group: '.'}},
+		{"de_CH.UTF-8@euro", Locale{Tag: "de-CH", Lang: "en", Point: '.', Group: '\''}},
+		{"fr-FR", Locale{Tag: "fr-FR", Lang: "en", Point: ',', Group: '\u00a0'}},
+		{"en-US", Locale{Tag: "en-US", Lang: "en", Point: '.', Group: ','}},
+		{"es-MX", Locale{Tag: "es-MX", Lang: "en", Point: '.', Group: ','}},
+		{"es-ES", Locale{Tag: "es-ES", Lang: "en", Point: ',', Group: '.'}},
+		{"zh-CN", Locale{Tag: "zh-CN", Lang: "zh", Point: '.', Group: ','}},
+		{"zh-Hans-CN", Locale{Tag: "zh-Hans-CN", Lang: "zh", Point: '.', Group: ','}},
+		{"ZH_cn", Locale{Tag: "zh-CN", Lang: "zh", Point: '.', Group: ','}},
+		// 未设置时使用英文
+		{"", Locale{Tag: "en", Lang: "en", Point: '.', Group: ','}},
+		{"C", Locale{Tag: "en", Lang: "en", Point: '.', Group: ','}},
+		{"POSIX", Locale{Tag: "en", Lang: "en", Point: '.', Group: ','}},
+	}
+	for _, tt := range tests {
+		if got := New(tt.tag); got != tt.want {
+			t.Errorf("New(%q) = %+v, want %+v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		lcAll, lcMessages, lang string
+		want                    string
+	}{
+		{"de_DE.UTF-8", "fr_FR.UTF-8", "en_US.UTF-8", "de-DE"},
+		{"", "fr_FR.UTF-8", "en_US.UTF-8", "fr-FR"},
+		{"", "", "zh_CN.UTF-8", "zh-CN"},
+		{"C", "", "de_DE.UTF-8", "de-DE"},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", tt.lcMessages)
+		t.Setenv("LANG", tt.lang)
+		if got := Detect().Tag; got != tt.want {
+			t.Errorf("Detect with LC_ALL=%q LC_MESSAGES=%q LANG=%q = %q, want %q", tt.lcAll, tt.lcMessages, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	zh, en := New("zh-CN"), New("en-US")
+	if got := zh.T("History"); got != "历史" {
+		t.Errorf("zh T(History) = %q", got)
+	}
+	if got := en.T("History"); got != "History" {
+		t.Errorf("en T(History) = %q", got)
+	}
+	if got := zh.T("Copied: %s", "42"); got != "已复制：42" {
+		t.Errorf("zh T(Copied) = %q", got)
+	}
+	// 没有翻译的文字返回原文
+	if got := zh.T("no such message %d", 1); got != "no such message 1" {
+		t.Errorf("zh T of an untranslated message = %q", got)
+	}
+}
@@ -0,0 +1,16 @@
+package i18n
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// systemLocale 读取 macOS 系统偏好设置中的语言区域，如 "zh_CN"；
+// 从 Finder 启动的程序没有 LANG 环境变量
+func systemLocale() string {
+	out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
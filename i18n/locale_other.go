@@ -0,0 +1,8 @@
+//go:build !windows && !darwin
+
+package i18n
+
+// systemLocale 其他系统只使用环境变量
+func systemLocale() string {
+	return ""
+}
@@ -0,0 +1,41 @@
+package i18n
+
+import "strings"
+
+// 小数点为逗号、千位分隔符为点的语言：1.234,56
+var commaPointDotGroup = []string{"de", "es", "it", "nl", "pt", "id", "tr", "da", "el", "ro", "hr", "sl", "sr", "vi"}
+
+// 小数点为逗号、千位分隔符为空格的语言：1 234,56
+var commaPointSpaceGroup = []string{"fr", "ru", "uk", "pl", "cs", "sk", "fi", "sv", "nb", "no", "hu", "bg", "et", "lv", "lt"}
+
+// regionSeparators 与语言默认不同的地区，键为 "语言-地区"
+var regionSeparators = map[string][2]rune{
+	"de-CH": {'.', '\''},
+	"it-CH": {'.', '\''},
+	"de-LI": {'.', '\''},
+	"es-MX": {'.', ','},
+	"es-US": {'.', ','},
+}
+
+// separators 返回语言和地区使用的小数点和千位分隔符，默认为 1,234.56
+func separators(lang, region string) (point, group rune) {
+	// 地区可能在文字代码之后，如 zh-Hans-CN
+	if i := strings.LastIndex(region, "-"); i >= 0 {
+		region = region[i+1:]
+	}
+	if s, ok := regionSeparators[lang+"-"+region]; ok {
+		return s[0], s[1]
+	}
+	for _, l := range commaPointDotGroup {
+		if l == lang {
+			return ',', '.'
+		}
+	}
+	for _, l := range commaPointSpaceGroup {
+		if l == lang {
+			// 不换行空格，数字不会在分隔处折行
+			return ',', '\u00a0'
+		}
+	}
+	return '.', ','
+}
@@ -0,0 +1,64 @@
+package i18n
+
+// zh 简体中文
+var zh = map[string]string{
+	// 窗口和标题栏
+	"Go Calculator based on Gio": "基于 Gio 的 Go 计算器",
+	"About":                      "关于",
+	"History":                    "历史",
+	"Basic":                      "标准",
+	"Sci":                        "科学",
+	"Prog":                       "程序员",
+
+	// 显示区
+	"Error": "错误",
+	"Undo":  "撤销",
+	"Redo":  "重做",
+
+	// 计算错误
+	"Invalid expression":                         "表达式无效",
+	"Cannot divide by zero":                      "除数不能为零",
+	"Result too large":                           "结果过大",
+	"Invalid input":                              "输入无效",
+	"Precision lost":                             "精度不足",
+	"The expression could not be understood.":    "无法识别这个表达式。",
+	"Division by zero has no result.":            "除以零没有结果。",
+	"The result is too large to be represented.": "结果超出了能表示的范围。",
+	"The value is outside the domain of the function, e.g. the logarithm or square root of a negative number.": "数值超出了函数的定义域，例如负数的对数或平方根。",
+	"The value is too large or too small for the function to give a meaningful result.":                        "数值过大或过小，函数无法得到有意义的结果。",
+	"%s Press AC, CE or ⌫, or enter a new number to continue.":                                                 "%s按 AC、CE 或 ⌫，或输入新的数字后继续。",
+
+	// 历史和存储面板
+	"Clear":                   "清除",
+	"No history yet":          "暂无历史记录",
+	"Memory":                  "存储",
+	"Mem":                     "存储",
+	"Nothing saved in memory": "存储器中没有内容",
+	"name":                    "名称",
+
+	// 剪贴板
+	"Copy":                   "复制",
+	"Copy with separators":   "复制（带分隔符）",
+	"Copy expression":        "复制表达式",
+	"Paste":                  "粘贴",
+	"Copy as Chinese amount": "复制为大写金额",
+	"Copied":                 "已复制",
+	"Copied: %s":             "已复制：%s",
+	"Cannot paste: %v":       "无法粘贴：%v",
+	"Cannot convert: %v":     "无法转换：%v",
+
+	// 主题
+	"Theme: %s":     "主题：%s",
+	"dark":          "深色",
+	"light":         "浅色",
+	"high-contrast": "高对比度",
+	"colorblind":    "色盲友好",
+
+	// 关于窗口
+	"Author: ":                         "作者：",
+	"Purpose: ":                        "用途：",
+	"How to write Go GUI app with gio": "演示如何用 Gio 编写 Go GUI 应用",
+	"Version: ":                        "版本：",
+	"GitCommit: ":                      "提交：",
+	"BuildTime: ":                      "构建时间：",
+}
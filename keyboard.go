@@ -0,0 +1,102 @@
+package main
+
+import (
+	"image/color"
+	"io"
+	"time"
+
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/transfer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+// flashDuration 按下键盘按键时对应屏幕按键高亮的时长
+const flashDuration = 150 * time.Millisecond
+
+// handleKeyboard 接收窗口的键盘输入，按下的按键与点击屏幕按键效果相同
+func (c *Calculator) handleKeyboard(gtx layout.Context) {
+	// 整个窗口作为键盘事件的接收区域
+	area := clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops)
+	event.Op(gtx.Ops, c)
+	area.Pop()
+
+	// 启动时和点击按键后获取焦点，从存储名字编辑框等控件取回键盘输入
+	if c.refocus {
+		gtx.Execute(key.FocusCmd{Tag: c})
+		c.refocus = false
+	}
+
+	filters := []event.Filter{
+		key.FocusFilter{Target: c},
+		transfer.TargetFilter{Target: c, Type: "application/text"},
+		// 复制（Shift 时带分隔符）和粘贴
+		key.Filter{Focus: c, Required: key.ModShortcut, Optional: key.ModShift, Name: "C"},
+		key.Filter{Focus: c, Required: key.ModShortcut, Name: "V"},
+		// 切换主题
+		key.Filter{Focus: c, Required: key.ModShortcut, Name: "T"},
+	}
+	for _, f := range c.keyFilters() {
+		filters = append(filters, f)
+	}
+	for {
+		ev, ok := gtx.Event(filters...)
+		if !ok {
+			break
+		}
+		switch ev := ev.(type) {
+		case key.Event:
+			if ev.State != key.Press {
+				break
+			}
+			switch {
+			case ev.Name == "C" && ev.Modifiers.Contain(key.ModShortcut|key.ModShift):
+				c.contextMenuAction(gtx, "Copy with separators")
+			case ev.Name == "C" && ev.Modifiers.Contain(key.ModShortcut):
+				c.contextMenuAction(gtx, "Copy")
+			case ev.Name == "V" && ev.Modifiers.Contain(key.ModShortcut):
+				c.contextMenuAction(gtx, "Paste")
+			case ev.Name == "T" && ev.Modifiers.Contain(key.ModShortcut):
+				c.cycleTheme(gtx)
+			case ev.Name == key.NameEscape && c.menu.open:
+				c.menu.open = false
+				c.invalidate()
+			default:
+				c.pressKey(gtx, c.keyForEvent(ev))
+			}
+		case transfer.DataEvent:
+			data := ev.Open()
+			text, err := io.ReadAll(data)
+			data.Close()
+			if err == nil {
+				c.paste(gtx, string(text))
+			}
+		case key.EditEvent:
+			for _, r := range ev.Text {
+				c.pressKey(gtx, c.keyForChar(r))
+			}
+		}
+	}
+}
+
+// pressKey 处理键盘按下的按键，并让对应的屏幕按键闪烁
+func (c *Calculator) pressKey(gtx layout.Context, k *keyDef) {
+	if k == nil || !c.keyEnabled(k) {
+		return
+	}
+	c.flashLabel = k.name()
+	c.flashUntil = gtx.Now.Add(flashDuration)
+	c.activate(k)
+}
+
+// flashColor 屏幕按键正在闪烁时返回高亮色，否则返回原来的颜色
+func (c *Calculator) flashColor(gtx layout.Context, name string, bg color.NRGBA) color.NRGBA {
+	if name != c.flashLabel || !gtx.Now.Before(c.flashUntil) {
+		return bg
+	}
+	// 闪烁结束时重绘，恢复原来的颜色
+	gtx.Execute(op.InvalidateCmd{At: c.flashUntil})
+	return c.palette.Flash
+}
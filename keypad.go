@@ -0,0 +1,343 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"gocalc/engine"
+)
+
+// keyRole 按键的样式角色，决定按键的颜色
+type keyRole int
+
+const (
+	roleDigit     keyRole = iota // 数字、小数点、函数和位运算：白色文字
+	roleOperator                 // 运算符、清除、括号等：亮绿色文字
+	roleEquals                   // 等号：绿色背景
+	roleBackspace                // 退格：红色文字
+)
+
+// keyDef 一个按键的定义，按键的布局、颜色、点击和键盘输入都只由它决定
+type keyDef struct {
+	ID     engine.Key          // 发送给计算引擎的按键
+	Label  string              // 显示的文字，为空时使用 ID
+	Role   keyRole             // 样式角色
+	Span   int                 // 占据的列数，0 表示一列
+	Action func(c *Calculator) // 不为 nil 时代替向计算引擎发送 ID
+
+	// 自定义颜色，透明表示使用角色的颜色
+	Background color.NRGBA
+	Foreground color.NRGBA
+
+	// 键盘快捷键
+	Chars     string     // 输入其中任一字符时触发
+	Keys      []key.Name // 按下其中任一功能键时触发
+	Ctrl      key.Name   // 与 Ctrl（macOS 为 Cmd）同时按下时触发
+	CtrlShift key.Name   // 与 Ctrl+Shift 同时按下时触发
+}
+
+// keypad 按行排列的按键
+type keypad [][]keyDef
+
+// blank 判断是否为只占位置的空位
+func (k *keyDef) blank() bool {
+	return k.ID == "" && k.Action == nil
+}
+
+// name 按键的唯一名字，用于键盘闪烁
+func (k *keyDef) name() string {
+	if k.ID != "" {
+		return string(k.ID)
+	}
+	return k.Label
+}
+
+// label 按键上显示的文字
+func (k *keyDef) label() string {
+	if k.Label != "" {
+		return k.Label
+	}
+	return string(k.ID)
+}
+
+func (k *keyDef) span() int {
+	return max(k.Span, 1)
+}
+
+// columns 键盘的列数，即各行按键占据列数的最大值
+func (kp keypad) columns() int {
+	columns := 0
+	for _, row := range kp {
+		n := 0
+		for i := range row {
+			n += row[i].span()
+		}
+		columns = max(columns, n)
+	}
+	return columns
+}
+
+// row 返回第 i 行，超出行数时为空行
+func (kp keypad) row(i int) []keyDef {
+	if i < len(kp) {
+		return kp[i]
+	}
+	return nil
+}
+
+// digit 数字键，输入同一个字符触发
+func digit(d string) keyDef {
+	return keyDef{ID: engine.Key(d), Chars: d}
+}
+
+// basicKeypad 标准键盘
+var basicKeypad = keypad{
+	{
+		{ID: engine.KeyClear, Role: roleOperator, Keys: []key.Name{key.NameEscape}},
+		{ID: engine.KeyNegate, Role: roleOperator},
+		{ID: engine.KeyPercent, Role: roleOperator, Chars: "%"},
+		{ID: engine.KeyBackspace, Role: roleBackspace, Keys: []key.Name{key.NameDeleteBackward}},
+	},
+	{
+		{ID: engine.KeyClearEntry, Role: roleOperator, Span: 2, Keys: []key.Name{key.NameDeleteForward}},
+		{ID: engine.KeyLParen, Role: roleOperator, Chars: "("},
+		{ID: engine.KeyRParen, Role: roleOperator, Chars: ")"},
+	},
+	{
+		{ID: engine.KeyDeltaPct, Role: roleOperator, Span: 2},
+		{ID: engine.KeyMarkup, Role: roleOperator},
+		{ID: engine.KeyMargin, Role: roleOperator},
+	},
+	{digit("7"), digit("8"), digit("9"), {ID: engine.KeyDiv, Role: roleOperator, Chars: "/÷"}},
+	{digit("4"), digit("5"), digit("6"), {ID: engine.KeyMul, Role: roleOperator, Chars: "*xX×"}},
+	{digit("1"), digit("2"), digit("3"), {ID: engine.KeySub, Role: roleOperator, Chars: "-−"}},
+	{
+		{ID: engine.KeyDot, Chars: ".,"},
+		digit("0"),
+		{ID: engine.KeyEquals, Role: roleEquals, Chars: "=", Keys: []key.Name{key.NameReturn, key.NameEnter}},
+		{ID: engine.KeyAdd, Role: roleOperator, Chars: "+"},
+	},
+}
+
+// scientificKeypad 科学计算键盘，位于标准键盘左侧，与其逐行对齐
+var scientificKeypad = keypad{
+	{{ID: engine.KeySin, Chars: "s"}, {ID: engine.KeyCos, Chars: "o"}, {ID: engine.KeyTan, Chars: "t"}, {ID: engine.KeyPi, Chars: "p"}},
+	{{ID: engine.KeyAsin}, {ID: engine.KeyAcos}, {ID: engine.KeyAtan}, {ID: engine.KeyE, Chars: "e"}},
+	{{ID: engine.KeySinh}, {ID: engine.KeyCosh}, {ID: engine.KeyTanh}, {ID: engine.KeyFactorial, Chars: "!"}},
+	{{ID: engine.KeyAsinh}, {ID: engine.KeyAcosh}, {ID: engine.KeyAtanh}, {ID: engine.KeyAbs}},
+	{{ID: engine.KeyLn, Chars: "n"}, {ID: engine.KeyLog, Chars: "l"}, {ID: engine.KeyLog2}, {ID: engine.KeyReciprocal, Chars: "r"}},
+	{{ID: engine.KeySquare, Chars: "q"}, {ID: engine.KeyPower, Chars: "^"}, {ID: engine.KeySqrt, Chars: "@"}, {ID: engine.KeyRoot}},
+}
+
+// programmerKeypad 程序员键盘，A–F 只在十六进制下可用
+var programmerKeypad = keypad{
+	{{ID: engine.KeyWord, Role: roleOperator}, {ID: engine.KeySign, Role: roleOperator}, {ID: engine.KeyLsh, Chars: "<"}, {ID: engine.KeyRsh, Chars: ">"}},
+	{{ID: engine.KeyA, Chars: "aA"}, {ID: engine.KeyB, Chars: "bB"}, {ID: engine.KeyRol}, {ID: engine.KeyRor}},
+	{{ID: engine.KeyC, Chars: "cC"}, {ID: engine.KeyD, Chars: "dD"}, {ID: engine.KeyAnd, Chars: "&"}, {ID: engine.KeyOr, Chars: "|"}},
+	{{ID: engine.KeyE16, Chars: "eE"}, {ID: engine.KeyF, Chars: "fF"}, {ID: engine.KeyXor, Chars: "^"}, {ID: engine.KeyNot, Chars: "~"}},
+	{{}, {}, {ID: engine.KeyNand}, {ID: engine.KeyNor}},
+	{{}, {}, {ID: engine.KeyMod, Chars: "%"}, {}},
+}
+
+// memoryKeypad 显示区下方的存储按键，最后一个打开存储面板
+var memoryKeypad = keypad{{
+	{ID: engine.KeyMC, Ctrl: "L"},
+	{ID: engine.KeyMR, Ctrl: "R"},
+	{ID: engine.KeyMAdd, Ctrl: "P"},
+	{ID: engine.KeyMSub, Ctrl: "Q"},
+	{ID: engine.KeyMS, Ctrl: "M"},
+	{Label: "Mem", Action: func(c *Calculator) { c.togglePanel(panelMemory) }},
+}}
+
+// undoKeypad 显示区右上角的撤销和重做按键
+var undoKeypad = keypad{{
+	{ID: engine.KeyUndo, Ctrl: "Z"},
+	{ID: engine.KeyRedo, Ctrl: "Y", CtrlShift: "Z"},
+}}
+
+// visibleKeypads 当前显示的键盘，扩展键盘在前，快捷键冲突时优先
+func (c *Calculator) visibleKeypads() []keypad {
+	if side := c.sideKeypad(); side != nil {
+		return []keypad{side, c.keypads.Basic, memoryKeypad, undoKeypad}
+	}
+	return []keypad{c.keypads.Basic, memoryKeypad, undoKeypad}
+}
+
+// clickable 返回按键的点击状态
+func (c *Calculator) clickable(k *keyDef) *widget.Clickable {
+	btn, ok := c.clicks[k]
+	if !ok {
+		btn = new(widget.Clickable)
+		c.clicks[k] = btn
+	}
+	return btn
+}
+
+// activate 执行按键：调用 Action 或向计算引擎发送按键
+func (c *Calculator) activate(k *keyDef) {
+	if k.blank() || !c.keyEnabled(k) {
+		return
+	}
+	if k.Action != nil {
+		k.Action(c)
+		return
+	}
+	c.handleButtonClick(string(k.ID))
+}
+
+// handleKeypadEvents 处理所有可见按键的点击
+func (c *Calculator) handleKeypadEvents(gtx layout.Context) {
+	for _, kp := range c.visibleKeypads() {
+		for i := range kp {
+			for j := range kp[i] {
+				k := &kp[i][j]
+				if !k.blank() && c.clickable(k).Clicked(gtx) {
+					c.activate(k)
+				}
+			}
+		}
+	}
+}
+
+// layoutKeyRow 一行按键，按 Span 分配宽度，与 columns 列的网格对齐
+func (c *Calculator) layoutKeyRow(gtx layout.Context, row []keyDef, columns, buttonSize int) layout.Dimensions {
+	gap := 0
+	if columns > 1 {
+		gap = max((gtx.Constraints.Max.X-buttonSize*columns)/(columns-1), 0)
+	}
+	if len(row) == 0 {
+		return layout.Dimensions{Size: image.Pt(gtx.Constraints.Max.X, buttonSize)}
+	}
+	spacer := func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: image.Pt(gap, 0)}
+	}
+	var children []layout.FlexChild
+	for i := range row {
+		if i > 0 {
+			children = append(children, layout.Rigid(spacer))
+		}
+		k := &row[i]
+		width := buttonSize*k.span() + gap*(k.span()-1)
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.layoutKey(gtx, k, width, buttonSize)
+		}))
+	}
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+}
+
+// layoutKey 绘制一个按键
+func (c *Calculator) layoutKey(gtx layout.Context, k *keyDef, width, height int) layout.Dimensions {
+	gtx.Constraints = layout.Exact(image.Pt(width, height))
+	if k.blank() {
+		// 空位只占据位置
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	}
+
+	// 确定按键样式
+	bgColor, textColor := c.palette.keyColors(k.Role)
+	if k.Background.A != 0 {
+		bgColor = k.Background
+	}
+	if k.Foreground.A != 0 {
+		textColor = k.Foreground
+	}
+	bgColor = c.flashColor(gtx, k.name(), bgColor)
+	if !c.keyEnabled(k) {
+		textColor = c.palette.Disabled
+	}
+
+	// 绘制圆角背景
+	r := op.Record(gtx.Ops)
+	rect := image.Rectangle{Max: gtx.Constraints.Max}
+	rr := clip.UniformRRect(rect, gtx.Dp(unit.Dp(10)))
+	paint.FillShape(gtx.Ops, bgColor, rr.Op(gtx.Ops))
+	call := r.Stop()
+
+	label := material.Body1(c.theme, k.label())
+	label.Color = textColor
+	label.Alignment = text.Middle
+	label.TextSize = buttonTextSize(k.label())
+
+	// 点击区域和文字居中布局
+	return c.clickable(k).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		call.Add(gtx.Ops)
+		return layout.Center.Layout(gtx, label.Layout)
+	})
+}
+
+// buttonTextSize 按键文字大小，函数名等较长的标签使用小一些的字体
+func buttonTextSize(label string) unit.Sp {
+	if len([]rune(label)) > 2 {
+		return unit.Sp(18)
+	}
+	return unit.Sp(28)
+}
+
+// keyForChar 查找输入的字符对应的可见按键
+func (c *Calculator) keyForChar(r rune) *keyDef {
+	return c.findKey(func(k *keyDef) bool { return strings.ContainsRune(k.Chars, r) })
+}
+
+// keyForEvent 查找功能键或 Ctrl 组合键对应的可见按键
+func (c *Calculator) keyForEvent(ev key.Event) *keyDef {
+	if ev.Modifiers.Contain(key.ModShortcut | key.ModShift) {
+		return c.findKey(func(k *keyDef) bool { return k.CtrlShift == ev.Name })
+	}
+	if ev.Modifiers.Contain(key.ModShortcut) {
+		return c.findKey(func(k *keyDef) bool { return k.Ctrl == ev.Name })
+	}
+	return c.findKey(func(k *keyDef) bool {
+		for _, name := range k.Keys {
+			if name == ev.Name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// findKey 按优先级在可见键盘中查找第一个满足条件的按键
+func (c *Calculator) findKey(match func(k *keyDef) bool) *keyDef {
+	for _, kp := range c.visibleKeypads() {
+		for i := range kp {
+			for j := range kp[i] {
+				if k := &kp[i][j]; !k.blank() && match(k) {
+					return k
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// keyFilters 可见按键的功能键和 Ctrl 组合键对应的事件过滤器
+func (c *Calculator) keyFilters() []key.Filter {
+	var filters []key.Filter
+	for _, kp := range c.visibleKeypads() {
+		for _, row := range kp {
+			for _, k := range row {
+				for _, name := range k.Keys {
+					filters = append(filters, key.Filter{Focus: c, Name: name})
+				}
+				if k.Ctrl != "" {
+					filters = append(filters, key.Filter{Focus: c, Required: key.ModShortcut, Name: k.Ctrl})
+				}
+				if k.CtrlShift != "" {
+					filters = append(filters, key.Filter{Focus: c, Required: key.ModShortcut | key.ModShift, Name: k.CtrlShift})
+				}
+			}
+		}
+	}
+	return filters
+}
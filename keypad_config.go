@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"gioui.org/io/key"
+
+	"gocalc/engine"
+)
+
+// keypadFileName 键盘布局文件名，位于用户配置目录下的 gocalc 目录中
+const keypadFileName = "keypad.json"
+
+// keypadFile 键盘布局文件内容，每种键盘可选，缺少的使用内置布局：
+//
+//	{"basic":[[{"key":"7"},{"key":"8"},{"key":"9"}],[{"key":"0","span":3}]]}
+type keypadFile struct {
+	Basic      [][]keyRecord `json:"basic"`
+	Scientific [][]keyRecord `json:"scientific"`
+	Programmer [][]keyRecord `json:"programmer"`
+}
+
+// keyRecord 文件中的一个按键，空对象为空位；key 为内置按键时未填写的字段沿用内置定义
+type keyRecord struct {
+	Key        string   `json:"key"`        // 计算器按键，如 "7"、"sin"、"⌫"
+	Label      string   `json:"label"`      // 显示的文字
+	Role       string   `json:"role"`       // digit、operator、equals 或 backspace
+	Span       int      `json:"span"`       // 占据的列数
+	Value      string   `json:"value"`      // 输入的十进制常数，如 "9.80665"、"6.6743e-11"，代替 key
+	Action     string   `json:"action"`     // history、memory 或 mode，代替 key
+	Background string   `json:"background"` // 背景色 #RRGGBB 或 #RRGGBBAA
+	Color      string   `json:"color"`      // 文字颜色
+	Chars      string   `json:"chars"`      // 键盘快捷键字符
+	Keys       []string `json:"keys"`       // 键盘功能键，如 "Enter"
+	Ctrl       string   `json:"ctrl"`       // Ctrl 组合键，如 "K"
+	CtrlShift  string   `json:"ctrlShift"`  // Ctrl+Shift 组合键
+}
+
+// keypadSet 一组键盘：标准键盘和科学、程序员模式左侧的扩展键盘
+type keypadSet struct {
+	Basic      keypad
+	Scientific keypad
+	Programmer keypad
+}
+
+// defaultKeypads 内置键盘
+var defaultKeypads = keypadSet{
+	Basic:      basicKeypad,
+	Scientific: scientificKeypad,
+	Programmer: programmerKeypad,
+}
+
+// keyRoles 文件中的样式角色名
+var keyRoles = map[string]keyRole{
+	"digit":     roleDigit,
+	"operator":  roleOperator,
+	"equals":    roleEquals,
+	"backspace": roleBackspace,
+}
+
+// keyActions 文件中可用的界面操作
+var keyActions = map[string]func(c *Calculator){
+	"history": func(c *Calculator) { c.togglePanel(panelHistory) },
+	"memory":  func(c *Calculator) { c.togglePanel(panelMemory) },
+	"mode":    func(c *Calculator) { c.cycleKeypadMode() },
+}
+
+// keyNames 文件中可用的功能键名
+var keyNames = map[string]key.Name{
+	"Enter":       key.NameReturn,
+	"Return":      key.NameReturn,
+	"KeypadEnter": key.NameEnter,
+	"Escape":      key.NameEscape,
+	"Backspace":   key.NameDeleteBackward,
+	"Delete":      key.NameDeleteForward,
+	"Space":       key.NameSpace,
+	"Tab":         key.NameTab,
+}
+
+// loadKeypads 读取用户的键盘布局文件，文件不存在或有错误时使用内置布局
+func loadKeypads() keypadSet {
+	path, err := configPath(keypadFileName)
+	if err != nil {
+		return defaultKeypads
+	}
+	keypads, err := readKeypads(path)
+	if err != nil {
+		log.Printf("keypad layout %s ignored: %v", path, err)
+		return defaultKeypads
+	}
+	return keypads
+}
+
+// readKeypads 读取并检查键盘布局文件
+func readKeypads(path string) (keypadSet, error) {
+	keypads := defaultKeypads
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return keypads, nil
+	}
+	if err != nil {
+		return keypads, err
+	}
+	var file keypadFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return keypads, err
+	}
+	for _, kp := range []struct {
+		name    string
+		records [][]keyRecord
+		keypad  *keypad
+	}{
+		{"basic", file.Basic, &keypads.Basic},
+		{"scientific", file.Scientific, &keypads.Scientific},
+		{"programmer", file.Programmer, &keypads.Programmer},
+	} {
+		if kp.records == nil {
+			continue
+		}
+		parsed, err := parseKeypad(kp.records)
+		if err != nil {
+			return defaultKeypads, fmt.Errorf("%s: %w", kp.name, err)
+		}
+		*kp.keypad = parsed
+	}
+	return keypads, nil
+}
+
+// parseKeypad 把文件中的按键转换为键盘
+func parseKeypad(records [][]keyRecord) (keypad, error) {
+	if len(records) == 0 {
+		return nil, errors.New("no rows")
+	}
+	kp := make(keypad, len(records))
+	for i, row := range records {
+		kp[i] = make([]keyDef, len(row))
+		for j, r := range row {
+			k, err := r.keyDef()
+			if err != nil {
+				return nil, fmt.Errorf("row %d key %d: %w", i+1, j+1, err)
+			}
+			kp[i][j] = k
+		}
+	}
+	if kp.columns() == 0 {
+		return nil, errors.New("no keys")
+	}
+	return kp, nil
+}
+
+// keyDef 把文件中的按键转换为按键定义
+func (r keyRecord) keyDef() (keyDef, error) {
+	var k keyDef
+	switch {
+	case r.Value != "":
+		// 文件中的常数与语言区域无关，总是使用 '.' 小数点
+		value, err := engine.ParseDecimal(r.Value)
+		if err == nil {
+			_, err = engine.New().InsertValue(value)
+		}
+		if err != nil {
+			return k, fmt.Errorf("value %q: %w", r.Value, err)
+		}
+		k.Action = func(c *Calculator) { c.insertValue(value) }
+		k.Label = r.Value
+	case r.Action != "":
+		action, ok := keyActions[r.Action]
+		if !ok {
+			return k, fmt.Errorf("unknown action %q", r.Action)
+		}
+		k.Action = action
+		k.Label = r.Action
+	case r.Key != "":
+		builtin, ok := builtinKey(engine.Key(r.Key))
+		if !ok {
+			return k, fmt.Errorf("unknown key %q", r.Key)
+		}
+		k = builtin
+	}
+	if k.blank() {
+		if r.Label != "" {
+			return k, errors.New("key, value or action required")
+		}
+		return keyDef{Span: r.Span}, nil
+	}
+
+	if r.Label != "" {
+		k.Label = r.Label
+	}
+	if r.Role != "" {
+		role, ok := keyRoles[r.Role]
+		if !ok {
+			return k, fmt.Errorf("unknown role %q", r.Role)
+		}
+		k.Role = role
+	}
+	if r.Span < 0 {
+		return k, fmt.Errorf("invalid span %d", r.Span)
+	}
+	if r.Span > 0 {
+		k.Span = r.Span
+	}
+	var err error
+	if k.Background, err = parseColor(r.Background); err != nil {
+		return k, err
+	}
+	if k.Foreground, err = parseColor(r.Color); err != nil {
+		return k, err
+	}
+	if r.Chars != "" {
+		k.Chars = r.Chars
+	}
+	if r.Keys != nil {
+		k.Keys = nil
+		for _, name := range r.Keys {
+			n, ok := keyNames[name]
+			if !ok {
+				return k, fmt.Errorf("unknown key name %q", name)
+			}
+			k.Keys = append(k.Keys, n)
+		}
+	}
+	if r.Ctrl != "" {
+		k.Ctrl = key.Name(strings.ToUpper(r.Ctrl))
+	}
+	if r.CtrlShift != "" {
+		k.CtrlShift = key.Name(strings.ToUpper(r.CtrlShift))
+	}
+	return k, nil
+}
+
+// builtinKey 在内置键盘中查找按键，进制和角度等不在键盘上的按键只使用默认样式
+func builtinKey(id engine.Key) (keyDef, bool) {
+	for _, kp := range []keypad{basicKeypad, scientificKeypad, programmerKeypad, memoryKeypad, undoKeypad} {
+		for _, row := range kp {
+			for _, k := range row {
+				if k.ID == id {
+					k.Span = 0
+					return k, true
+				}
+			}
+		}
+	}
+	for _, b := range baseLabels {
+		if b.key == id {
+			return keyDef{ID: id, Role: roleOperator}, true
+		}
+	}
+	if id == engine.KeyAngle {
+		return keyDef{ID: id, Role: roleOperator}, true
+	}
+	return keyDef{}, false
+}
+
+// parseColor 解析 #RRGGBB 或 #RRGGBBAA 颜色，空字符串表示使用角色的颜色
+func parseColor(s string) (color.NRGBA, error) {
+	if s == "" {
+		return color.NRGBA{}, nil
+	}
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// insertValue 输入常数按键的值
+func (c *Calculator) insertValue(value engine.Decimal) {
+	if state, err := c.engine.InsertValue(value); err == nil {
+		c.state = state
+	}
+	c.refocus = true
+	c.menu.open = false
+	c.invalidate()
+}
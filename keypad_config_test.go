@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestReadKeypadExamples(t *testing.T) {
+	for _, path := range []string{"docs/keypads/constants.json", "docs/keypads/numeric.json"} {
+		if _, err := readKeypads(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
+
+func TestKeyRecordValue(t *testing.T) {
+	for _, value := range []string{"9.80665", "6.6743e-11", "-1.5", "299792458"} {
+		if _, err := (keyRecord{Value: value}).keyDef(); err != nil {
+			t.Errorf("value %q: %v", value, err)
+		}
+	}
+	// 常数只能是使用 '.' 小数点的十进制数
+	for _, value := range []string{"9,80665", "1,234.5", "2π", "1 + 2", "1e99999", "abc"} {
+		if _, err := (keyRecord{Value: value}).keyDef(); err == nil {
+			t.Errorf("value %q accepted", value)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// errInterrupted 编辑时按下了 Ctrl+C
+var errInterrupted = errors.New("interrupted")
+
+// lineEditor 终端中的单行编辑器：左右移动、行首行尾、删除和上下键翻阅历史。
+// 终端需已切换为逐字符读取、不回显的模式
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history []string // 输入过的行，最近的在最后
+}
+
+// editState 正在编辑的一行
+type editState struct {
+	prompt  string
+	line    []rune
+	cursor  int
+	index   int    // 正在显示的历史记录，等于历史长度时为新的一行
+	pending []rune // 翻阅历史前正在输入的内容
+}
+
+func newLineEditor(in io.Reader, out io.Writer) *lineEditor {
+	return &lineEditor{in: bufio.NewReader(in), out: out}
+}
+
+// readLine 显示提示并读取一行；空行时按 Ctrl+D 返回 io.EOF，按 Ctrl+C 返回 errInterrupted
+func (l *lineEditor) readLine(prompt string) (string, error) {
+	s := &editState{prompt: prompt, index: len(l.history)}
+	l.redraw(s)
+	for {
+		r, _, err := l.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(l.out, "\r\n")
+			line := string(s.line)
+			if strings.TrimSpace(line) != "" && (len(l.history) == 0 || l.history[len(l.history)-1] != line) {
+				l.history = append(l.history, line)
+			}
+			return line, nil
+		case 3: // Ctrl+C
+			fmt.Fprint(l.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl+D
+			if len(s.line) == 0 {
+				fmt.Fprint(l.out, "\r\n")
+				return "", io.EOF
+			}
+			s.delete()
+		case 1: // Ctrl+A
+			s.cursor = 0
+		case 5: // Ctrl+E
+			s.cursor = len(s.line)
+		case 2: // Ctrl+B
+			s.cursor = max(s.cursor-1, 0)
+		case 6: // Ctrl+F
+			s.cursor = min(s.cursor+1, len(s.line))
+		case 11: // Ctrl+K 删除到行尾
+			s.line = s.line[:s.cursor]
+		case 21: // Ctrl+U 删除到行首
+			s.line = s.line[s.cursor:]
+			s.cursor = 0
+		case 12: // Ctrl+L 清屏
+			fmt.Fprint(l.out, "\x1b[H\x1b[2J")
+		case 16: // Ctrl+P
+			l.recall(s, -1)
+		case 14: // Ctrl+N
+			l.recall(s, 1)
+		case 8, 127: // Backspace
+			if s.cursor > 0 {
+				s.cursor--
+				s.delete()
+			}
+		case 27:
+			l.escape(s)
+		default:
+			if r >= ' ' {
+				s.line = append(s.line[:s.cursor], append([]rune{r}, s.line[s.cursor:]...)...)
+				s.cursor++
+			}
+		}
+		l.redraw(s)
+	}
+}
+
+// escape 处理方向键、Home、End 和 Delete 的转义序列：ESC [ A、ESC O H、ESC [ 3 ~ 等
+func (l *lineEditor) escape(s *editState) {
+	intro, _, err := l.in.ReadRune()
+	if err != nil || intro != '[' && intro != 'O' {
+		return
+	}
+	var param []rune
+	for {
+		r, _, err := l.in.ReadRune()
+		if err != nil {
+			return
+		}
+		if r >= '0' && r <= '9' || r == ';' {
+			param = append(param, r)
+			continue
+		}
+		switch {
+		case r == 'A':
+			l.recall(s, -1)
+		case r == 'B':
+			l.recall(s, 1)
+		case r == 'C':
+			s.cursor = min(s.cursor+1, len(s.line))
+		case r == 'D':
+			s.cursor = max(s.cursor-1, 0)
+		case r == 'H', r == '~' && (string(param) == "1" || string(param) == "7"):
+			s.cursor = 0
+		case r == 'F', r == '~' && (string(param) == "4" || string(param) == "8"):
+			s.cursor = len(s.line)
+		case r == '~' && string(param) == "3":
+			s.delete()
+		}
+		return
+	}
+}
+
+// recall 显示上一条（step 为 -1）或下一条（step 为 1）历史记录
+func (l *lineEditor) recall(s *editState, step int) {
+	index := s.index + step
+	if index < 0 || index > len(l.history) {
+		return
+	}
+	if s.index == len(l.history) {
+		s.pending = s.line
+	}
+	s.index = index
+	if index == len(l.history) {
+		s.line = s.pending
+	} else {
+		s.line = []rune(l.history[index])
+	}
+	s.cursor = len(s.line)
+}
+
+// delete 删除光标处的字符
+func (s *editState) delete() {
+	if s.cursor < len(s.line) {
+		s.line = append(s.line[:s.cursor], s.line[s.cursor+1:]...)
+	}
+}
+
+// redraw 重新绘制当前行并把光标移到编辑位置
+func (l *lineEditor) redraw(s *editState) {
+	fmt.Fprintf(l.out, "\r%s%s\x1b[K", s.prompt, string(s.line))
+	if back := len(s.line) - s.cursor; back > 0 {
+		fmt.Fprintf(l.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// 按键用控制字符和终端转义序列表示
+const (
+	keyUp    = "\x1b[A"
+	keyDown  = "\x1b[B"
+	keyRight = "\x1b[C"
+	keyLeft  = "\x1b[D"
+	keyHome  = "\x1b[H"
+	keyEnd   = "\x1bOF"
+	keyDel   = "\x1b[3~"
+)
+
+// readLines 把 input 当作终端按键交给 lineEditor，返回读到的各行和最后的错误
+func readLines(input string) ([]string, error) {
+	l := newLineEditor(strings.NewReader(input), io.Discard)
+	var lines []string
+	for {
+		line, err := l.readLine("> ")
+		if err != nil {
+			return lines, err
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	tests := []struct {
+		input string
+		lines []string
+	}{
+		{"1+2\r", []string{"1+2"}},
+		{"1+2\n3\r", []string{"1+2", "3"}},
+		// 退格和 Delete
+		{"12\x7f3\r", []string{"13"}},
+		{"12\b\b\b3\r", []string{"3"}},
+		{"123" + keyLeft + keyLeft + keyDel + "\r", []string{"13"}},
+		{"123" + keyHome + "\x04\r", []string{"23"}},
+		// 光标移动后在中间插入
+		{"13" + keyLeft + "2\r", []string{"123"}},
+		{"23\x011\r", []string{"123"}},
+		{"2" + keyHome + "1" + keyEnd + "3\r", []string{"123"}},
+		{"2\x1b[1~1\x1b[4~3\r", []string{"123"}},
+		{"13\x02\x02\x062\r", []string{"123"}},
+		{"1" + keyRight + keyRight + "2\r", []string{"12"}},
+		{"1" + keyLeft + keyLeft + "0\r", []string{"01"}},
+		// Ctrl+K、Ctrl+U
+		{"1234\x02\x02\x0b\r", []string{"12"}},
+		{"1234\x02\x02\x15\r", []string{"34"}},
+		// 上下键翻阅历史，回到新的一行时恢复翻阅前的输入
+		{"1\r2\r" + keyUp + keyUp + "0\r", []string{"1", "2", "10"}},
+		{"1\r2\r" + keyUp + keyUp + keyUp + "\r", []string{"1", "2", "1"}},
+		{"1\r3" + keyUp + keyDown + "4\r", []string{"1", "34"}},
+		{"1\r2\r\x10\x10\x0e\r", []string{"1", "2", "2"}},
+		{keyDown + "5\r", []string{"5"}},
+		// 空行和重复的行不进入历史
+		{"1\r1\r\r" + keyUp + keyUp + "\r", []string{"1", "1", "", "1"}},
+		// 不认识的控制字符和转义序列被忽略
+		{"1\x07\x1b[5~\x1bx2\r", []string{"12"}},
+	}
+	for _, tt := range tests {
+		lines, err := readLines(tt.input)
+		if !errors.Is(err, io.EOF) || strings.Join(lines, "|") != strings.Join(tt.lines, "|") {
+			t.Errorf("input %q: got %q, %v; want %q", tt.input, lines, err, tt.lines)
+		}
+	}
+}
+
+func TestLineEditorInterrupt(t *testing.T) {
+	l := newLineEditor(strings.NewReader("12\x03\x04"), io.Discard)
+	if _, err := l.readLine("> "); !errors.Is(err, errInterrupted) {
+		t.Errorf("Ctrl+C: got %v, want errInterrupted", err)
+	}
+	// 空行时 Ctrl+D 结束输入
+	if _, err := l.readLine("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("Ctrl+D: got %v, want io.EOF", err)
+	}
+}
+
+func TestLineEditorRedraw(t *testing.T) {
+	var out strings.Builder
+	l := newLineEditor(strings.NewReader("ab"+keyLeft+"\r"), &out)
+	if _, err := l.readLine("> "); err != nil {
+		t.Fatal(err)
+	}
+	want := "\r> \x1b[K" + "\r> a\x1b[K" + "\r> ab\x1b[K" + "\r> ab\x1b[K\x1b[1D" + "\r\n"
+	if out.String() != want {
+		t.Errorf("output %q, want %q", out.String(), want)
+	}
+}
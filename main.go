@@ -1,12 +1,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
 	"os"
-	"strconv"
-	"strings"
+	"time"
 
 	"gioui.org/app"
 	"gioui.org/layout"
@@ -17,75 +18,119 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
-)
 
-// 颜色定义
-var (
-	darkGreen   = color.NRGBA{R: 30, G: 60, B: 50, A: 255}    // 深绿色背景
-	buttonGreen = color.NRGBA{R: 40, G: 80, B: 65, A: 255}    // 按钮深绿色
-	brightGreen = color.NRGBA{R: 0, G: 200, B: 100, A: 255}   // 亮绿色（运算符和AC等）
-	equalsGreen = color.NRGBA{R: 0, G: 220, B: 110, A: 255}   // 等号按钮绿色
-	white       = color.NRGBA{R: 255, G: 255, B: 255, A: 255} // 白色文字
-	lightGray   = color.NRGBA{R: 200, G: 200, B: 200, A: 255} // 浅灰色（之前的计算）
-	red         = color.NRGBA{R: 255, G: 80, B: 80, A: 255}   // 红色（退格图标）
+	"gocalc/engine"
+	"gocalc/i18n"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "repl":
+			os.Exit(runREPL(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "serve":
+			os.Exit(runServe(os.Args[2:], os.Stderr))
+		}
+	}
+	// 带参数或从管道读入表达式时只在命令行中计算，不打开窗口
+	cmd, ok, err := parseCLI(os.Args[1:], os.Stdin, os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitOK)
+		}
+		fmt.Fprintf(os.Stderr, "gocalc: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if ok {
+		os.Exit(cmd.run(os.Stdin, os.Stdout, os.Stderr))
+	}
+
 	go func() {
 		defer os.Exit(0)
+		calc := NewCalculator()
 		w := &app.Window{}
 		w.Option(
-			app.Title("Go Calculator based on Gio"),
+			app.Title(calc.tr("Go Calculator based on Gio")),
 			app.Size(unit.Dp(400), unit.Dp(700)),
 		)
-		calc := NewCalculator()
 		calc.Run(w)
 	}()
 	app.Main()
 }
 
 type Calculator struct {
-	display *widget.Editor
-	buttons [][]widget.Clickable
-	theme   *material.Theme
+	keypads  keypadSet                     // 标准、科学和程序员键盘的布局
+	clicks   map[*keyDef]*widget.Clickable // 按键定义对应的点击状态
+	baseBtns [4]widget.Clickable           // 程序员模式的进制读数
+	bitBtns  [64]widget.Clickable          // 程序员模式的位面板
+	theme    *material.Theme
+	palette  Theme   // 当前主题的颜色
+	themes   []Theme // 可切换的内置和用户主题
+	settings settings
 
 	window *app.Window
 
 	// 标题栏按钮
 	menuBtn    widget.Clickable
 	historyBtn widget.Clickable
+	modeBtn    widget.Clickable
+	angleBtn   widget.Clickable
+
+	// 历史和存储面板
+	panel        panelKind
+	historyList  widget.List
+	historyRows  []historyRow
+	clearHistory widget.Clickable
+	historyStore *historyStore // 为 nil 时历史只保存在内存中
+	memoryList   widget.List
+	memoryRows   []*memoryRow
+	clearMemory  widget.Clickable
+
+	// 键盘输入
+	refocus    bool      // 下一帧获取键盘焦点
+	flashLabel string    // 正在闪烁的按键
+	flashUntil time.Time // 闪烁结束的时间
+
+	// 剪贴板
+	menu        contextMenu
+	errorTip    errorTip
+	notice      string // 表达式行临时显示的提示
+	noticeColor color.NRGBA
+	noticeUntil time.Time
+
+	// 键盘模式
+	keypadMode keypadMode
+	keypad     keypadMode // 当前帧显示的键盘
+
+	// 界面语言和数字格式
+	locale i18n.Locale
 
 	// 计算状态
-	currentValue     float64
-	previousValue    float64
-	operation        string
-	shouldReset      bool
-	previousExprText string
+	engine *engine.Engine
+	state  engine.State
 }
 
 func NewCalculator() *Calculator {
-	display := &widget.Editor{
-		SingleLine: true,
-		ReadOnly:   true,
-		Alignment:  text.End,
-	}
-	display.SetText("0")
-
-	// 创建按钮网格 5行4列
-	buttons := make([][]widget.Clickable, 5)
-	for i := range buttons {
-		buttons[i] = make([]widget.Clickable, 4)
-	}
-
-	theme := material.NewTheme()
-	theme.Palette.Fg = white
-	theme.Palette.Bg = darkGreen
-
-	return &Calculator{
-		display: display,
-		buttons: buttons,
-		theme:   theme,
+	settings := loadSettings()
+	locale := settings.locale()
+	eng := engine.New()
+	eng.SetFormat(settings.format(locale))
+	c := &Calculator{
+		keypads:     loadKeypads(),
+		clicks:      make(map[*keyDef]*widget.Clickable),
+		themes:      loadThemes(),
+		settings:    settings,
+		historyList: widget.List{List: layout.List{Axis: layout.Vertical}},
+		memoryList:  widget.List{List: layout.List{Axis: layout.Vertical}},
+		refocus:     true,
+		locale:      locale,
+		engine:      eng,
+		state:       eng.State(),
 	}
+	c.setTheme(settings.Theme)
+	c.loadHistory()
+	c.loadMemory()
+	return c
 }
 
 func (c *Calculator) Run(w *app.Window) error {
@@ -107,11 +152,22 @@ func (c *Calculator) Run(w *app.Window) error {
 }
 
 func (c *Calculator) Layout(gtx layout.Context) layout.Dimensions {
+	c.syncKeypad(gtx)
+	c.handleKeyboard(gtx)
 	c.handleEvents(gtx)
 
-	// 填充深绿色背景
-	paint.Fill(gtx.Ops, darkGreen)
+	// 填充主题背景
+	paint.Fill(gtx.Ops, c.palette.Background)
 
+	// 窄窗口中面板覆盖键盘区域，宽窗口中显示在右侧
+	overlay := c.panel != panelNone && !c.panelDocked(gtx)
+	return c.withSidePanel(gtx, func(gtx layout.Context) layout.Dimensions {
+		return c.layoutCalculator(gtx, overlay)
+	})
+}
+
+// layoutCalculator 标题栏、显示区域和键盘，overlay 为真时键盘区域显示打开的面板
+func (c *Calculator) layoutCalculator(gtx layout.Context, overlay bool) layout.Dimensions {
 	return layout.Flex{
 		Axis:    layout.Vertical,
 		Spacing: layout.SpaceStart,
@@ -122,7 +178,18 @@ func (c *Calculator) Layout(gtx layout.Context) layout.Dimensions {
 		}),
 		// 显示区域
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return c.layoutDisplay(gtx)
+			return c.withContextMenu(gtx, c.layoutDisplay)
+		}),
+		// 程序员模式的位面板
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if c.keypad != keypadProgrammer {
+				return layout.Dimensions{}
+			}
+			return c.layoutBitField(gtx)
+		}),
+		// 存储按键
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.layoutMemoryKeys(gtx)
 		}),
 		// 按钮网格
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
@@ -132,6 +199,9 @@ func (c *Calculator) Layout(gtx layout.Context) layout.Dimensions {
 				Left:   unit.Dp(20),
 				Right:  unit.Dp(20),
 			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if overlay {
+					return c.layoutPanel(gtx)
+				}
 				return c.layoutButtons(gtx)
 			})
 		}),
@@ -149,24 +219,44 @@ func (c *Calculator) layoutTitleBar(gtx layout.Context) layout.Dimensions {
 			Axis:    layout.Horizontal,
 			Spacing: layout.SpaceBetween,
 		}.Layout(gtx,
-			// 关于按钮（左上角）
+			// 关于和历史按钮（左上角）
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return c.menuBtn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-					label := material.Body2(c.theme, "About")
-					label.Color = white
-					label.Alignment = text.Start
-					label.TextSize = unit.Sp(14)
-					return label.Layout(gtx)
-				})
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return c.menuBtn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							label := material.Body2(c.theme, c.tr("About"))
+							label.Color = c.palette.Text
+							label.Alignment = text.Start
+							label.TextSize = unit.Sp(14)
+							return label.Layout(gtx)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(15)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return c.historyBtn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							label := material.Body2(c.theme, c.tr("History"))
+							label.Color = c.palette.Text
+							if c.panel == panelHistory {
+								label.Color = c.palette.Accent
+							}
+							label.TextSize = unit.Sp(14)
+							return label.Layout(gtx)
+						})
+					}),
+				)
 			}),
 			// Standard 文字（中间）
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				label := material.Body1(c.theme, "Gocalc by panyingyun")
-				label.Color = white
+				label.Color = c.palette.Text
 				label.Alignment = text.Middle
 				label.TextSize = unit.Sp(16)
 				return label.Layout(gtx)
 			}),
+			// 模式切换（右上角）
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return c.layoutModeSwitch(gtx)
+			}),
 		)
 	})
 }
@@ -186,13 +276,17 @@ func (c *Calculator) drawMenuIcon(gtx layout.Context) {
 			Max: image.Pt(int(xOffset+lineWidth), int(y+lineHeight)),
 		}
 		rr := clip.UniformRRect(rect, 1)
-		paint.FillShape(gtx.Ops, white, rr.Op(gtx.Ops))
+		paint.FillShape(gtx.Ops, c.palette.Text, rr.Op(gtx.Ops))
 	}
 }
 
 func (c *Calculator) layoutDisplay(gtx layout.Context) layout.Dimensions {
 	// 固定显示区域高度，足够显示三行内容
 	fixedHeight := gtx.Dp(unit.Dp(180)) // 固定高度约180dp，可容纳三行
+	if c.keypad == keypadProgrammer {
+		// 程序员模式额外显示四行进制读数
+		fixedHeight += gtx.Dp(unit.Dp(80))
+	}
 
 	return layout.Inset{
 		Top:    unit.Dp(30),
@@ -209,20 +303,37 @@ func (c *Calculator) layoutDisplay(gtx layout.Context) layout.Dimensions {
 			Spacing:   layout.SpaceEnd, // 内容靠底部，间距紧密
 			Alignment: layout.End,      // 右对齐
 		}.Layout(gtx,
-			// 第一行：预留空间（顶部空白，推动内容到底部）
+			// 第一行：预留空间（顶部空白，推动内容到底部），存储器有值时左上角显示 M，右上角为撤销和重做
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-				return layout.Dimensions{
-					Size: image.Pt(gtx.Constraints.Max.X, gtx.Constraints.Max.Y),
+				size := image.Pt(gtx.Constraints.Max.X, gtx.Constraints.Max.Y)
+				if c.state.Memory {
+					label := material.Caption(c.theme, "M")
+					label.Color = c.palette.Accent
+					label.TextSize = unit.Sp(14)
+					label.Layout(gtx)
+				}
+				layout.NE.Layout(gtx, c.layoutUndoKeys)
+				return layout.Dimensions{Size: size}
+			}),
+			// 程序员模式：HEX/DEC/OCT/BIN 读数
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if c.keypad != keypadProgrammer {
+					return layout.Dimensions{}
 				}
+				return c.layoutBaseReadouts(gtx)
 			}),
 			// 第二行：之前的计算表达式（小字、灰色）
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				if c.previousExprText != "" {
+				expression, color := c.state.Expression, c.palette.Secondary
+				if notice, noticeColor := c.currentNotice(gtx); notice != "" {
+					expression, color = notice, noticeColor
+				}
+				if expression != "" {
 					return layout.Inset{
 						Bottom: unit.Dp(5), // 表达式和结果之间的小间距
 					}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-						label := material.Caption(c.theme, c.previousExprText)
-						label.Color = lightGray
+						label := material.Caption(c.theme, expression)
+						label.Color = color
 						label.Alignment = text.End
 						label.TextSize = unit.Sp(14)
 						return label.Layout(gtx)
@@ -230,34 +341,55 @@ func (c *Calculator) layoutDisplay(gtx layout.Context) layout.Dimensions {
 				}
 				return layout.Dimensions{}
 			}),
-			// 第三行：当前结果（大字体、白色）- 紧挨着表达式
+			// 第三行：当前结果（大字体、白色）- 紧挨着表达式；出错时显示错误信息，鼠标悬停时显示说明
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				displayText := c.display.Text()
-				label := material.H1(c.theme, displayText)
-				label.Color = white
-				label.Alignment = text.End
-				label.TextSize = unit.Sp(48)
-				return label.Layout(gtx)
+				return c.withErrorTip(gtx, func(gtx layout.Context) layout.Dimensions {
+					display, color := c.state.Display, c.palette.Text
+					if c.state.Err != nil {
+						display, color = c.errorMessage(c.state.Err), c.palette.Danger
+					}
+					label := material.H1(c.theme, display)
+					label.Color = color
+					label.Alignment = text.End
+					label.MaxLines = 1
+					// 结果过长时自动缩小字体，避免被截断
+					label.TextSize = c.fitTextSize(gtx, label, unit.Sp(48), unit.Sp(20))
+					return label.Layout(gtx)
+				})
 			}),
 		)
 	})
 }
 
-func (c *Calculator) layoutButtons(gtx layout.Context) layout.Dimensions {
-	buttonLabels := [][]string{
-		{"AC", "±", "%", "<-"},
-		{"7", "8", "9", "÷"},
-		{"4", "5", "6", "×"},
-		{"1", "2", "3", "-"},
-		{".", "0", "=", "+"},
+// fitTextSize 返回能让文字在当前宽度内完整显示的最大字号
+func (c *Calculator) fitTextSize(gtx layout.Context, label material.LabelStyle, maxSize, minSize unit.Sp) unit.Sp {
+	measure := gtx
+	measure.Constraints = layout.Constraints{Max: image.Pt(1<<20, gtx.Constraints.Max.Y)}
+	for size := maxSize; size > minSize; size -= 2 {
+		label.TextSize = size
+		// 只测量尺寸，丢弃绘制操作
+		m := op.Record(gtx.Ops)
+		dims := label.Layout(measure)
+		m.Stop()
+		if dims.Size.X <= gtx.Constraints.Max.X {
+			return size
+		}
 	}
+	return minSize
+}
+
+// layoutButtons 按钮网格：标准键盘，科学和程序员模式在左侧加上扩展键盘
+func (c *Calculator) layoutButtons(gtx layout.Context) layout.Dimensions {
+	basic, side := c.keypads.Basic, c.sideKeypad()
+	columns := basic.columns() + side.columns()
+	rows := max(len(basic), len(side))
 
-	// 计算按钮大小：4列布局，留出间距
+	// 计算按钮大小：按列数和行数平分，留出间距
 	availableWidth := gtx.Constraints.Max.X
 	availableHeight := gtx.Constraints.Max.Y
 	buttonGap := gtx.Dp(unit.Dp(10))
-	buttonSize := (availableWidth - buttonGap*3) / 4
-	maxHeight := (availableHeight - buttonGap*4) / 5
+	buttonSize := (availableWidth - buttonGap*(columns-1)) / columns
+	maxHeight := (availableHeight - buttonGap*(rows-1)) / rows
 	if buttonSize > maxHeight {
 		buttonSize = maxHeight
 	}
@@ -265,153 +397,25 @@ func (c *Calculator) layoutButtons(gtx layout.Context) layout.Dimensions {
 		buttonSize = 40
 	}
 
+	children := make([]layout.FlexChild, rows)
+	for i := range children {
+		children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.withSideRow(gtx, side.row(i), buttonSize, func(gtx layout.Context) layout.Dimensions {
+				return c.layoutKeyRow(gtx, basic.row(i), basic.columns(), buttonSize)
+			})
+		})
+	}
 	return layout.Flex{
 		Axis:    layout.Vertical,
 		Spacing: layout.SpaceBetween,
-	}.Layout(gtx,
-		// 第一行：AC ± % ⌫
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return c.layoutButtonRow(gtx, buttonLabels[0], 0, buttonSize, buttonGap)
-		}),
-		// 第二行：7 8 9 ÷
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return c.layoutButtonRow(gtx, buttonLabels[1], 1, buttonSize, buttonGap)
-		}),
-		// 第三行：4 5 6 ×
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return c.layoutButtonRow(gtx, buttonLabels[2], 2, buttonSize, buttonGap)
-		}),
-		// 第四行：1 2 3 -
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return c.layoutButtonRow(gtx, buttonLabels[3], 3, buttonSize, buttonGap)
-		}),
-		// 第五行：. 0 = +
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return c.layoutButtonRow(gtx, buttonLabels[4], 4, buttonSize, buttonGap)
-		}),
-	)
-}
-
-func (c *Calculator) layoutButtonRow(gtx layout.Context, labels []string, row int, buttonSize, buttonGap int) layout.Dimensions {
-	return layout.Flex{
-		Axis:    layout.Horizontal,
-		Spacing: layout.SpaceBetween,
-	}.Layout(gtx,
-		c.button(gtx, &c.buttons[row][0], labels[0], buttonSize),
-		c.button(gtx, &c.buttons[row][1], labels[1], buttonSize),
-		c.button(gtx, &c.buttons[row][2], labels[2], buttonSize),
-		c.button(gtx, &c.buttons[row][3], labels[3], buttonSize),
-	)
-}
-
-func (c *Calculator) buttonWide(gtx layout.Context, btn *widget.Clickable, label string, width, height int) layout.FlexChild {
-	return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-		gtx.Constraints = layout.Exact(image.Pt(width, height))
-
-		// 确定按钮样式
-		bgColor, textColor := c.getButtonColors(label)
-
-		// 绘制方形背景
-		r := op.Record(gtx.Ops)
-		rect := image.Rectangle{Max: gtx.Constraints.Max}
-		radius := gtx.Dp(unit.Dp(10)) // 方形，无圆角
-		rr := clip.UniformRRect(rect, radius)
-		paint.FillShape(gtx.Ops, bgColor, rr.Op(gtx.Ops))
-		call := r.Stop()
-
-		// 按钮文字 - 使用 Center 布局确保完全居中
-		labelWidget := material.Body1(c.theme, label)
-		labelWidget.Color = textColor
-		if label == "⌫" {
-			// 退格按钮使用特殊颜色
-			labelWidget.Color = red
-		}
-		labelWidget.Alignment = text.Middle
-		labelWidget.TextSize = unit.Sp(28) // 更大的字体
-
-		// 点击区域和文字居中布局
-		clickable := btn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-			call.Add(gtx.Ops)
-			// 使用 Center 布局确保文字在按钮中完全居中
-			return layout.Center.Layout(gtx, labelWidget.Layout)
-		})
-
-		return clickable
-	})
-}
-
-func (c *Calculator) button(gtx layout.Context, btn *widget.Clickable, label string, buttonSize int) layout.FlexChild {
-	return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-		gtx.Constraints = layout.Exact(image.Pt(buttonSize, buttonSize))
-
-		// 确定按钮样式
-		bgColor, textColor := c.getButtonColors(label)
-
-		// 绘制方形背景
-		r := op.Record(gtx.Ops)
-		rect := image.Rectangle{Max: gtx.Constraints.Max}
-		radius := gtx.Dp(unit.Dp(10)) // 方形，无圆角
-		rr := clip.UniformRRect(rect, radius)
-		paint.FillShape(gtx.Ops, bgColor, rr.Op(gtx.Ops))
-		call := r.Stop()
-
-		// 按钮文字 - 使用 Center 布局确保完全居中
-		labelWidget := material.Body1(c.theme, label)
-		labelWidget.Color = textColor
-		if label == "⌫" {
-			// 退格按钮使用特殊颜色
-			labelWidget.Color = red
-		}
-		labelWidget.Alignment = text.Middle
-		labelWidget.TextSize = unit.Sp(28) // 更大的字体
-
-		// 点击区域和文字居中布局
-		clickable := btn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-			call.Add(gtx.Ops)
-			// 使用 Center 布局确保文字在按钮中完全居中
-			return layout.Center.Layout(gtx, labelWidget.Layout)
-		})
-
-		return clickable
-	})
-}
-
-func (c *Calculator) getButtonColors(label string) (bgColor, textColor color.NRGBA) {
-	switch label {
-	case "=":
-		return equalsGreen, white
-	case "+", "-", "×", "÷":
-		return buttonGreen, brightGreen
-	case "AC", "%":
-		return buttonGreen, brightGreen
-	case "⌫":
-		return buttonGreen, red
-	case "±":
-		return buttonGreen, brightGreen
-	default: // 数字和点
-		return buttonGreen, white
-	}
+	}.Layout(gtx, children...)
 }
 
 func (c *Calculator) handleEvents(gtx layout.Context) {
-	buttonLabels := [][]string{
-		{"AC", "±", "%", "⌫"},
-		{"7", "8", "9", "÷"},
-		{"4", "5", "6", "×"},
-		{"1", "2", "3", "-"},
-		{".", "0", "=", "+"},
-	}
-
-	for i := range c.buttons {
-		for j := range c.buttons[i] {
-			if c.buttons[i][j].Clicked(gtx) {
-				label := buttonLabels[i][j]
-				if label != "" {
-					c.handleButtonClick(label)
-				}
-			}
-		}
-	}
+	c.handleKeypadEvents(gtx)
+	c.handleModeEvents(gtx)
+	c.handleHistoryEvents(gtx)
+	c.handleMemoryEvents(gtx)
 
 	// 处理标题栏按钮
 	if c.menuBtn.Clicked(gtx) {
@@ -419,170 +423,30 @@ func (c *Calculator) handleEvents(gtx layout.Context) {
 		go func() {
 			aboutWindow := &app.Window{}
 			aboutWindow.Option(
-				app.Title("关于"),
+				app.Title(c.tr("About")),
 				app.Size(unit.Dp(380), unit.Dp(500)),
 			)
-			about := NewAboutWindow()
+			about := NewAboutWindow(c.locale, c.palette)
 			about.Run(aboutWindow)
 		}()
 	}
 }
 
 func (c *Calculator) handleButtonClick(label string) {
-	currentText := c.display.Text()
-
-	switch label {
-	case "AC": // 全部清除
-		c.reset()
-		c.updateDisplay("0")
-		c.previousExprText = ""
-	case "⌫": // 退格
-		if len(currentText) > 1 {
-			c.updateDisplay(currentText[:len(currentText)-1])
-		} else {
-			c.updateDisplay("0")
-		}
-	case "±": // 正负号
-		if currentText != "0" {
-			if strings.HasPrefix(currentText, "-") {
-				c.updateDisplay(currentText[1:])
-			} else {
-				c.updateDisplay("-" + currentText)
-			}
-		}
-	case "%": // 百分比
-		val := c.parseDisplay()
-		c.updateDisplay(c.formatNumber(val / 100))
-		c.shouldReset = true
-	case "=":
-		c.calculate()
-	case "+", "-", "×", "÷":
-		if c.operation != "" {
-			c.calculate()
-		}
-		c.previousValue = c.parseDisplay()
-		c.operation = label
-		c.shouldReset = true
-		// 更新之前的表达式显示
-		c.previousExprText = c.formatNumber(c.previousValue) + " " + label
-	case ".":
-		if c.shouldReset {
-			c.updateDisplay("0.")
-			c.shouldReset = false
-		} else if !strings.Contains(currentText, ".") {
-			c.updateDisplay(currentText + ".")
-		}
-	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
-		if c.shouldReset || currentText == "0" {
-			c.updateDisplay(label)
-			c.shouldReset = false
-		} else {
-			c.updateDisplay(currentText + label)
-		}
-		// 格式化显示（添加千位分隔符）
-		val := c.parseDisplay()
-		c.updateDisplay(c.formatNumber(val))
-	}
-}
-
-func (c *Calculator) calculate() {
-	current := c.parseDisplay()
-	var result float64
-
-	switch c.operation {
-	case "+":
-		result = c.previousValue + current
-	case "-":
-		result = c.previousValue - current
-	case "×":
-		result = c.previousValue * current
-	case "÷":
-		if current != 0 {
-			result = c.previousValue / current
-		} else {
-			c.updateDisplay("错误")
-			c.previousValue = 0
-			c.operation = ""
-			c.shouldReset = true
-			c.previousExprText = ""
-			return
-		}
-	default:
-		return
-	}
-
-	// 更新之前的表达式显示
-	c.previousExprText = c.formatNumber(c.previousValue) + " " + c.operation + " " + c.formatNumber(current)
-	c.updateDisplay(c.formatNumber(result))
-	c.previousValue = result
-	c.operation = ""
-	c.shouldReset = true
-}
-
-func (c *Calculator) parseDisplay() float64 {
-	text := strings.ReplaceAll(strings.TrimSpace(c.display.Text()), ",", "")
-	if text == "错误" {
-		return 0
-	}
-	val, err := strconv.ParseFloat(text, 64)
-	if err != nil {
-		return 0
-	}
-	return val
-}
-
-func (c *Calculator) formatNumber(num float64) string {
-	// 如果是整数，显示为整数；否则显示为小数
-	var str string
-	if num == float64(int64(num)) {
-		str = fmt.Sprintf("%.0f", num)
-	} else {
-		str = fmt.Sprintf("%g", num)
-	}
-
-	// 添加千位分隔符
-	parts := strings.Split(str, ".")
-	intPart := parts[0]
-	negative := false
-	if strings.HasPrefix(intPart, "-") {
-		intPart = intPart[1:]
-		negative = true
-	}
-	result := c.addCommas(intPart)
-	if len(parts) > 1 {
-		result += "." + parts[1]
-	}
-	if negative {
-		return "-" + result
-	}
-	return result
+	c.state = c.engine.Press(engine.Key(label))
+	c.refocus = true
+	c.menu.open = false
+	c.invalidate()
 }
 
-func (c *Calculator) addCommas(s string) string {
-	if len(s) <= 3 {
-		return s
-	}
-	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && (len(s)-i)%3 == 0 {
-			result.WriteString(",")
-		}
-		result.WriteRune(r)
-	}
-	return result.String()
+// tr 翻译界面文字
+func (c *Calculator) tr(msg string, args ...any) string {
+	return c.locale.T(msg, args...)
 }
 
-func (c *Calculator) updateDisplay(text string) {
-	c.display.SetText(text)
+// invalidate 状态改变后请求重绘
+func (c *Calculator) invalidate() {
 	if c.window != nil {
 		c.window.Invalidate()
 	}
 }
-
-func (c *Calculator) reset() {
-	c.currentValue = 0
-	c.previousValue = 0
-	c.operation = ""
-	c.shouldReset = false
-	c.previousExprText = ""
-}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"image"
+	"log"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"gocalc/engine"
+)
+
+// memoryRow 存储面板中一个存储单元的控件
+type memoryRow struct {
+	name   widget.Editor    // 存储单元名字，可直接编辑
+	recall widget.Clickable // 点击值：读取到当前输入
+	clear  widget.Clickable
+	add    widget.Clickable
+	sub    widget.Clickable
+}
+
+// layoutMemoryKeys 存储按键行
+func (c *Calculator) layoutMemoryKeys(gtx layout.Context) layout.Dimensions {
+	row := memoryKeypad[0]
+	children := make([]layout.FlexChild, len(row))
+	for i := range row {
+		k := &row[i]
+		children[i] = layout.Flexed(float32(k.span()), func(gtx layout.Context) layout.Dimensions {
+			return c.clickable(k).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				l := material.Body2(c.theme, c.tr(k.label()))
+				l.Color = c.palette.Text
+				switch {
+				case k.Action != nil && c.panel == panelMemory:
+					l.Color = c.palette.Accent
+				case (k.ID == engine.KeyMC || k.ID == engine.KeyMR || k.Action != nil) && !c.state.Memory:
+					// 存储器为空时清除和读取不可用
+					l.Color = c.palette.Disabled
+				}
+				l.Color = c.flashColor(gtx, k.name(), l.Color)
+				l.Alignment = text.Middle
+				l.TextSize = unit.Sp(14)
+				return layout.UniformInset(unit.Dp(6)).Layout(gtx, l.Layout)
+			})
+		})
+	}
+	return layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+	})
+}
+
+// layoutMemory 存储面板，最近存入的在最上面
+func (c *Calculator) layoutMemory(gtx layout.Context) layout.Dimensions {
+	slots := c.engine.Memory()
+	for len(c.memoryRows) < len(slots) {
+		c.memoryRows = append(c.memoryRows, &memoryRow{name: widget.Editor{SingleLine: true, Submit: true}})
+	}
+
+	// 面板背景
+	rect := clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Max}, gtx.Dp(unit.Dp(10)))
+	paint.FillShape(gtx.Ops, c.palette.Surface, rect.Op(gtx.Ops))
+
+	return layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			// 标题和清除按钮
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return c.layoutPanelHeader(gtx, c.tr("Memory"), &c.clearMemory)
+				})
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				if len(slots) == 0 {
+					label := material.Body2(c.theme, c.tr("Nothing saved in memory"))
+					label.Color = c.palette.Secondary
+					label.Alignment = text.Middle
+					return layout.Center.Layout(gtx, label.Layout)
+				}
+				return material.List(c.theme, &c.memoryList).Layout(gtx, len(slots), func(gtx layout.Context, i int) layout.Dimensions {
+					return c.layoutMemorySlot(gtx, c.memoryRows[i], slots[i])
+				})
+			}),
+		)
+	})
+}
+
+// layoutMemorySlot 一个存储单元：名字、值和 MC/M+/M− 按钮
+func (c *Calculator) layoutMemorySlot(gtx layout.Context, row *memoryRow, slot engine.MemorySlot) layout.Dimensions {
+	// 编辑名字时不覆盖用户的输入
+	if !gtx.Focused(&row.name) && row.name.Text() != slot.Name {
+		row.name.SetText(slot.Name)
+	}
+	value := c.formatMemory(slot.Value)
+
+	return layout.Inset{Bottom: unit.Dp(15)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Flex{
+			Axis:      layout.Vertical,
+			Alignment: layout.End,
+		}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Max.X = gtx.Dp(unit.Dp(80))
+						editor := material.Editor(c.theme, &row.name, c.tr("name"))
+						editor.Color = c.palette.Secondary
+						editor.HintColor = c.palette.Disabled
+						editor.TextSize = unit.Sp(14)
+						return editor.Layout(gtx)
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return row.recall.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							gtx.Constraints.Min.X = gtx.Constraints.Max.X
+							label := material.H5(c.theme, value)
+							label.Color = c.palette.Text
+							label.Alignment = text.End
+							label.MaxLines = 1
+							label.TextSize = c.fitTextSize(gtx, label, unit.Sp(24), unit.Sp(12))
+							return label.Layout(gtx)
+						})
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					c.memorySlotButton(&row.clear, "MC"),
+					c.memorySlotButton(&row.add, "M+"),
+					c.memorySlotButton(&row.sub, "M−"),
+				)
+			}),
+		)
+	})
+}
+
+// memorySlotButton 存储单元下方的小按钮
+func (c *Calculator) memorySlotButton(btn *widget.Clickable, label string) layout.FlexChild {
+	return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return btn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			l := material.Caption(c.theme, label)
+			l.Color = c.palette.Accent
+			l.TextSize = unit.Sp(12)
+			return layout.Inset{Left: unit.Dp(12), Top: unit.Dp(4)}.Layout(gtx, l.Layout)
+		})
+	})
+}
+
+// formatMemory 按当前模式格式化存储的值
+func (c *Calculator) formatMemory(d engine.Decimal) string {
+	format := c.engine.Format()
+	if c.state.Mode == engine.ModeProgrammer {
+		return format.Int(d.BigInt(), c.state.Base, c.state.Word)
+	}
+	return format.Decimal(d)
+}
+
+// handleMemoryEvents 处理存储面板
+func (c *Calculator) handleMemoryEvents(gtx layout.Context) {
+	if c.clearMemory.Clicked(gtx) {
+		c.handleButtonClick(string(engine.KeyMC))
+	}
+
+	slots := c.engine.Memory()
+	for i, row := range c.memoryRows {
+		if i >= len(slots) {
+			break
+		}
+		for {
+			ev, ok := row.name.Update(gtx)
+			if !ok {
+				break
+			}
+			switch ev.(type) {
+			case widget.ChangeEvent, widget.SubmitEvent:
+				if name := strings.TrimSpace(row.name.Text()); name != "" {
+					c.engine.RenameMemory(i, name)
+				}
+			}
+		}
+		switch {
+		case row.recall.Clicked(gtx):
+			c.state = c.engine.MemoryRecall(i)
+		case row.clear.Clicked(gtx):
+			c.state = c.engine.MemoryClear(i)
+		case row.add.Clicked(gtx):
+			c.state = c.engine.MemoryAdd(i)
+		case row.sub.Clicked(gtx):
+			c.state = c.engine.MemorySubtract(i)
+		default:
+			continue
+		}
+		c.invalidate()
+	}
+}
+
+// loadMemory 载入保存的存储单元，之后每次修改都写回存储器文件
+func (c *Calculator) loadMemory() {
+	store, err := newMemoryStore()
+	if err != nil {
+		log.Printf("memory persistence disabled: %v", err)
+		return
+	}
+	slots, err := store.Load()
+	if err != nil {
+		log.Printf("load memory: %v", err)
+	}
+	c.engine.SetMemory(slots)
+	c.state = c.engine.State()
+	c.engine.OnMemory(func(slots []engine.MemorySlot) {
+		if err := store.Save(slots); err != nil {
+			log.Printf("save memory: %v", err)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+
+	"gocalc/engine"
+)
+
+// memoryFileName 存储器文件名，位于用户配置目录下的 gocalc 目录中
+const memoryFileName = "memory.json"
+
+// memoryFile 存储器文件内容，最近存入的在前：
+//
+//	{"slots":[{"name":"M1","value":"42"}]}
+type memoryFile struct {
+	Slots []memoryRecord `json:"slots"`
+}
+
+type memoryRecord struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// memoryStore 保存在磁盘上的存储器
+type memoryStore struct {
+	path string
+}
+
+// newMemoryStore 在用户配置目录下创建存储器文件
+func newMemoryStore() (*memoryStore, error) {
+	path, err := configPath(memoryFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryStore{path: path}, nil
+}
+
+// Load 读取存储单元，跳过值无法解析的单元
+func (s *memoryStore) Load() ([]engine.MemorySlot, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file memoryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	var slots []engine.MemorySlot
+	for _, r := range file.Slots {
+		value, err := engine.ParseDecimal(r.Value)
+		if err != nil {
+			continue
+		}
+		slots = append(slots, engine.MemorySlot{Name: r.Name, Value: value})
+	}
+	return slots, nil
+}
+
+// Save 写入全部存储单元
+func (s *memoryStore) Save(slots []engine.MemorySlot) error {
+	file := memoryFile{Slots: make([]memoryRecord, len(slots))}
+	for i, m := range slots {
+		file.Slots[i] = memoryRecord{Name: m.Name, Value: m.Value.String()}
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, append(data, '\n'))
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"gocalc/engine"
+)
+
+// keypadMode 键盘模式
+type keypadMode int
+
+const (
+	keypadAuto       keypadMode = iota // 根据窗口宽高自动选择
+	keypadBasic                        // 标准键盘
+	keypadScientific                   // 科学计算键盘
+	keypadProgrammer                   // 程序员键盘
+)
+
+// baseLabels 程序员模式显示区中的进制读数，点击切换输入进制
+var baseLabels = []struct {
+	key  engine.Key
+	base int
+}{
+	{engine.KeyHex, 16},
+	{engine.KeyDec, 10},
+	{engine.KeyOct, 8},
+	{engine.KeyBin, 2},
+}
+
+// 切换模式时调整窗口大小
+var (
+	basicWindowSize      = [2]unit.Dp{400, 700}
+	scientificWindowSize = [2]unit.Dp{760, 700}
+	programmerWindowSize = [2]unit.Dp{760, 860} // 位面板需要更高的窗口
+)
+
+// currentKeypad 返回当前显示的键盘，自动模式下宽窗口（横屏）使用科学键盘
+func (c *Calculator) currentKeypad(gtx layout.Context) keypadMode {
+	if c.keypadMode != keypadAuto {
+		return c.keypadMode
+	}
+	if gtx.Constraints.Max.X > gtx.Constraints.Max.Y {
+		return keypadScientific
+	}
+	return keypadBasic
+}
+
+// engineMode 键盘对应的计算器模式
+func (m keypadMode) engineMode() engine.Mode {
+	switch m {
+	case keypadScientific:
+		return engine.ModeScientific
+	case keypadProgrammer:
+		return engine.ModeProgrammer
+	}
+	return engine.ModeBasic
+}
+
+// syncKeypad 确定当前帧的键盘，并让计算引擎切换到对应的模式
+func (c *Calculator) syncKeypad(gtx layout.Context) {
+	c.keypad = c.currentKeypad(gtx)
+	mode := c.keypad.engineMode()
+	if c.state.Mode != mode {
+		c.engine.SetMode(mode)
+		c.state = c.engine.State()
+	}
+}
+
+// hasSidePanel 当前键盘是否在标准键盘左侧显示扩展按键
+func (c *Calculator) hasSidePanel() bool {
+	return c.keypad == keypadScientific || c.keypad == keypadProgrammer
+}
+
+// sideKeypad 当前键盘左侧的扩展键盘，标准模式下为 nil
+func (c *Calculator) sideKeypad() keypad {
+	switch c.keypad {
+	case keypadScientific:
+		return c.keypads.Scientific
+	case keypadProgrammer:
+		return c.keypads.Programmer
+	}
+	return nil
+}
+
+// cycleKeypadMode 按 标准 → 科学 → 程序员 的顺序切换键盘，并调整窗口大小
+func (c *Calculator) cycleKeypadMode() {
+	size := scientificWindowSize
+	switch c.keypad {
+	case keypadBasic:
+		c.keypadMode = keypadScientific
+	case keypadScientific:
+		c.keypadMode = keypadProgrammer
+		size = programmerWindowSize
+	default:
+		c.keypadMode = keypadBasic
+		size = basicWindowSize
+	}
+	if c.window != nil {
+		c.window.Option(app.Size(size[0], size[1]))
+		c.window.Invalidate()
+	}
+}
+
+// keyEnabled 判断按键当前是否可用：没有可撤销或重做的操作时禁用撤销和重做，
+// 出错后禁用运算符和函数，程序员模式下禁用当前进制之外的数字和小数点
+func (c *Calculator) keyEnabled(k *keyDef) bool {
+	switch {
+	case c.state.Blocked(k.ID):
+		return false
+	case k.ID == engine.KeyUndo:
+		return c.state.CanUndo
+	case k.ID == engine.KeyRedo:
+		return c.state.CanRedo
+	case c.state.Mode != engine.ModeProgrammer:
+		return true
+	}
+	if k.ID == engine.KeyDot {
+		return false
+	}
+	if id := k.ID; len(id) == 1 && (id[0] >= '0' && id[0] <= '9' || id[0] >= 'A' && id[0] <= 'F') {
+		return engine.ValidDigit(id, c.state.Base)
+	}
+	return true
+}
+
+// layoutModeSwitch 标题栏右侧的模式切换按钮，以及角度单位或字长指示
+func (c *Calculator) layoutModeSwitch(gtx layout.Context) layout.Dimensions {
+	modeText := "Basic"
+	switch c.keypad {
+	case keypadScientific:
+		modeText = "Sci"
+	case keypadProgrammer:
+		modeText = "Prog"
+	}
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		// 科学模式显示角度单位，点击循环切换 DEG/RAD/GRAD；
+		// 程序员模式显示字长，点击循环切换 64/32/16/8 位
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			var indicator string
+			switch c.keypad {
+			case keypadScientific:
+				indicator = c.state.Angle.String()
+			case keypadProgrammer:
+				indicator = c.state.Word.String()
+			default:
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Right: unit.Dp(15)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return c.angleBtn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					label := material.Body2(c.theme, indicator)
+					label.Color = c.palette.Accent
+					label.TextSize = unit.Sp(14)
+					return label.Layout(gtx)
+				})
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.modeBtn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(c.theme, c.tr(modeText))
+				label.Color = c.palette.Text
+				label.Alignment = text.End
+				label.TextSize = unit.Sp(14)
+				return label.Layout(gtx)
+			})
+		}),
+	)
+}
+
+// layoutBaseReadouts 程序员模式下当前值的 HEX/DEC/OCT/BIN 读数，当前进制高亮
+func (c *Calculator) layoutBaseReadouts(gtx layout.Context) layout.Dimensions {
+	children := make([]layout.FlexChild, len(baseLabels))
+	for i, b := range baseLabels {
+		children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.baseBtns[i].Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				color := c.palette.Secondary
+				if c.state.Base == b.base {
+					color = c.palette.Accent
+				}
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Baseline,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						label := material.Caption(c.theme, string(b.key))
+						label.Color = color
+						label.TextSize = unit.Sp(12)
+						return label.Layout(gtx)
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						label := material.Caption(c.theme, c.engine.Format().Readout(c.state, b.base))
+						label.Color = color
+						label.Alignment = text.End
+						label.MaxLines = 1
+						label.TextSize = c.fitTextSize(gtx, label, unit.Sp(13), unit.Sp(8))
+						return label.Layout(gtx)
+					}),
+				)
+			})
+		})
+	}
+	return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
+
+// withSideRow 科学或程序员模式下在标准键盘的一行左侧加上对应的扩展按键
+func (c *Calculator) withSideRow(gtx layout.Context, side []keyDef, buttonSize int, basic layout.Widget) layout.Dimensions {
+	if !c.hasSidePanel() {
+		return basic(gtx)
+	}
+	// 两侧按列数分配宽度，让所有按键大小一致
+	columns := c.sideKeypad().columns()
+	return layout.Flex{
+		Axis: layout.Horizontal,
+	}.Layout(gtx,
+		layout.Flexed(float32(columns), func(gtx layout.Context) layout.Dimensions {
+			return c.layoutKeyRow(gtx, side, columns, buttonSize)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+		layout.Flexed(float32(c.keypads.Basic.columns()), basic),
+	)
+}
+
+// handleModeEvents 处理进制读数、位面板和标题栏的模式按钮
+func (c *Calculator) handleModeEvents(gtx layout.Context) {
+	c.handleBitEvents(gtx)
+	for i := range c.baseBtns {
+		if c.baseBtns[i].Clicked(gtx) {
+			c.handleButtonClick(string(baseLabels[i].key))
+		}
+	}
+
+	if c.modeBtn.Clicked(gtx) {
+		c.cycleKeypadMode()
+	}
+	if c.angleBtn.Clicked(gtx) {
+		if c.keypad == keypadProgrammer {
+			c.handleButtonClick(string(engine.KeyWord))
+		} else {
+			c.handleButtonClick(string(engine.KeyAngle))
+		}
+	}
+}
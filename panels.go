@@ -0,0 +1,86 @@
+package main
+
+import (
+	"image"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// panelKind 历史或存储面板
+type panelKind int
+
+const (
+	panelNone panelKind = iota
+	panelHistory
+	panelMemory
+)
+
+// sidePanelWidth 宽窗口中面板的宽度
+const sidePanelWidth = unit.Dp(300)
+
+// panelDocked 判断面板是否以侧栏形式显示，窗口足够宽时与键盘并排，否则覆盖键盘区域
+func (c *Calculator) panelDocked(gtx layout.Context) bool {
+	return gtx.Constraints.Max.X >= gtx.Dp(sidePanelWidth)*3
+}
+
+// togglePanel 打开或关闭面板，同一时间只显示一个面板
+func (c *Calculator) togglePanel(p panelKind) {
+	if c.panel == p {
+		c.panel = panelNone
+	} else {
+		c.panel = p
+	}
+	c.invalidate()
+}
+
+// withSidePanel 宽窗口中打开面板时在计算器右侧显示侧栏
+func (c *Calculator) withSidePanel(gtx layout.Context, main layout.Widget) layout.Dimensions {
+	if c.panel == panelNone || !c.panelDocked(gtx) {
+		return main(gtx)
+	}
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+		layout.Flexed(1, main),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			width := gtx.Dp(sidePanelWidth)
+			gtx.Constraints = layout.Exact(image.Pt(width, gtx.Constraints.Max.Y))
+			return c.layoutPanel(gtx)
+		}),
+	)
+}
+
+// layoutPanel 绘制当前打开的面板
+func (c *Calculator) layoutPanel(gtx layout.Context) layout.Dimensions {
+	switch c.panel {
+	case panelHistory:
+		return c.layoutHistory(gtx)
+	case panelMemory:
+		return c.layoutMemory(gtx)
+	}
+	return layout.Dimensions{}
+}
+
+// layoutPanelHeader 面板标题栏：左侧标题，右侧清除按钮
+func (c *Calculator) layoutPanelHeader(gtx layout.Context, title string, clear *widget.Clickable) layout.Dimensions {
+	return layout.Flex{
+		Axis:    layout.Horizontal,
+		Spacing: layout.SpaceBetween,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(c.theme, title)
+			label.Color = c.palette.Text
+			label.TextSize = unit.Sp(16)
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return clear.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(c.theme, c.tr("Clear"))
+				label.Color = c.palette.Accent
+				label.TextSize = unit.Sp(14)
+				return label.Layout(gtx)
+			})
+		}),
+	)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gocalc/engine"
+)
+
+// replHelp :help 显示的说明
+const replHelp = `Enter an expression to evaluate it, e.g. 2*(3+4) or sin(30).
+  name = expr        assign a variable; ans holds the last result
+  :mode [basic|sci|prog]
+                     show or change the mode; prog uses integer arithmetic
+  :precision [N]     show or change the significant digits of results
+  :angle [deg|rad|grad]
+                     show or change the angle unit
+  :vars              list variables
+  :help              show this help
+  :quit              leave (also Ctrl+D)
+Up and down arrows recall earlier lines.`
+
+// repl 交互式命令行，与图形界面使用同一个计算引擎
+type repl struct {
+	config engine.Config
+	mode   engine.Mode
+	out    io.Writer
+	errOut io.Writer
+	// interactive 输入来自终端：显示提示，错误位置标在输入行下方；
+	// 否则不显示提示，出错时先输出出错的行
+	interactive bool
+}
+
+// newREPL 以默认设置创建 repl，结果写到 out，错误写到 errOut
+func newREPL(out, errOut io.Writer) *repl {
+	r := &repl{config: engine.DefaultConfig, out: out, errOut: errOut}
+	r.config.Word = engine.DefaultWordSize
+	r.config.Vars = make(map[string]engine.Decimal)
+	return r
+}
+
+// runREPL 运行 gocalc repl，返回退出码
+func runREPL(args []string, stdin, stdout *os.File, stderr io.Writer) int {
+	if len(args) > 0 {
+		fmt.Fprintf(stderr, "gocalc: repl takes no arguments\n")
+		return exitUsage
+	}
+	r := newREPL(stdout, stderr)
+	restore, err := makeRaw(stdin, stdout)
+	if err != nil {
+		return r.runLines(stdin)
+	}
+	defer restore()
+	r.interactive = true
+	fmt.Fprintln(r.out, "gocalc repl, :help for help")
+	editor := newLineEditor(stdin, stdout)
+	for {
+		line, err := editor.readLine(r.prompt())
+		switch {
+		case errors.Is(err, errInterrupted):
+			continue
+		case errors.Is(err, io.EOF):
+			return exitOK
+		case err != nil:
+			fmt.Fprintf(r.errOut, "gocalc: %v\n", err)
+			return exitError
+		}
+		if _, quit := r.executeLine(line); quit {
+			return exitOK
+		}
+	}
+}
+
+// runLines 输入不是终端时逐行执行，有行出错时返回 exitError
+func (r *repl) runLines(in io.Reader) int {
+	code := exitOK
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		ok, quit := r.executeLine(scanner.Text())
+		if !ok {
+			code = exitError
+		}
+		if quit {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(r.errOut, "gocalc: %v\n", err)
+		return exitError
+	}
+	return code
+}
+
+// prompt 提示符显示当前模式，科学模式同时显示角度单位
+func (r *repl) prompt() string {
+	if r.mode == engine.ModeScientific {
+		return fmt.Sprintf("%s %s> ", r.mode, r.config.Angle)
+	}
+	return r.mode.String() + "> "
+}
+
+// executeLine 执行一行输入：命令、赋值或表达式；ok 为假表示出错
+func (r *repl) executeLine(line string) (ok, quit bool) {
+	input := strings.TrimSpace(line)
+	switch {
+	case input == "" || strings.HasPrefix(input, "#"):
+		return true, false
+	case input == "exit" || input == "quit":
+		return true, true
+	case strings.HasPrefix(input, ":"):
+		return r.command(input)
+	}
+
+	// 赋值：name = expr
+	name, expr, assign := strings.Cut(line, "=")
+	offset := 0
+	if assign {
+		name = strings.TrimSpace(name)
+		if err := engine.CheckVariable(name); err != nil {
+			r.fail(line, -1, err)
+			return false, false
+		}
+		offset = len([]rune(line)) - len([]rune(expr))
+	} else {
+		expr = line
+	}
+	d, err := r.config.Eval(expr)
+	if err != nil {
+		var syntax *engine.SyntaxError
+		if errors.As(err, &syntax) {
+			r.fail(line, offset+syntax.Pos, err)
+		} else {
+			r.fail(line, -1, err)
+		}
+		return false, false
+	}
+	r.config.Vars["ans"] = d
+	if assign {
+		r.config.Vars[name] = d
+		fmt.Fprintf(r.out, "%s = %s\n", name, r.format(d))
+	} else {
+		fmt.Fprintln(r.out, r.format(d))
+	}
+	return true, false
+}
+
+// command 执行 : 开头的命令
+func (r *repl) command(input string) (ok, quit bool) {
+	fields := strings.Fields(strings.TrimPrefix(input, ":"))
+	if len(fields) == 0 {
+		fields = []string{""}
+	}
+	name, args := fields[0], fields[1:]
+	if len(args) > 1 {
+		r.fail(input, -1, fmt.Errorf(":%s takes at most one argument", name))
+		return false, false
+	}
+	switch name {
+	case "q", "quit", "exit":
+		return true, true
+	case "help", "h":
+		fmt.Fprintln(r.out, replHelp)
+	case "vars":
+		names := make([]string, 0, len(r.config.Vars))
+		for name := range r.config.Vars {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Fprintf(r.out, "%s = %s\n", name, r.format(r.config.Vars[name]))
+		}
+	case "mode":
+		if len(args) == 1 {
+			mode, err := engine.ParseMode(args[0])
+			if err != nil {
+				r.fail(input, -1, err)
+				return false, false
+			}
+			r.mode = mode
+			r.config.Integer = mode == engine.ModeProgrammer
+		}
+		fmt.Fprintf(r.out, "mode %s\n", r.mode)
+	case "precision":
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n <= 0 || n > engine.MaxPrecision {
+				r.fail(input, -1, fmt.Errorf("invalid precision %q (1 to %d)", args[0], engine.MaxPrecision))
+				return false, false
+			}
+			r.config.Precision = n
+		}
+		fmt.Fprintf(r.out, "precision %d\n", r.config.Precision)
+	case "angle":
+		if len(args) == 1 {
+			mode, ok := parseAngle(args[0])
+			if !ok {
+				r.fail(input, -1, fmt.Errorf("invalid angle unit %q", args[0]))
+				return false, false
+			}
+			r.config.Angle = mode
+		}
+		fmt.Fprintf(r.out, "angle %s\n", r.config.Angle)
+	default:
+		r.fail(input, -1, fmt.Errorf("unknown command :%s, :help lists the commands", name))
+		return false, false
+	}
+	return true, false
+}
+
+// format 结果按当前精度显示，超出位数时使用科学记数法；程序员模式同时显示字长内的十六进制
+func (r *repl) format(d engine.Decimal) string {
+	if r.mode == engine.ModeProgrammer && d.IsInt() {
+		w := r.config.Word
+		hex := engine.Format{}.Int(d.BigInt(), 16, w)
+		return fmt.Sprintf("%s (hex %s)", w.Wrap(d.BigInt()), hex)
+	}
+	f := engine.Format{MaxDigits: r.config.Precision, Notation: engine.NotationScientific}
+	return f.Decimal(d)
+}
+
+// fail 输出错误；column 不小于 0 时用 ^ 标出输入中出错的位置
+func (r *repl) fail(line string, column int, err error) {
+	indent := 0
+	if r.interactive {
+		// 输入行已经显示在提示符后面
+		indent = len([]rune(r.prompt()))
+	} else if column >= 0 {
+		fmt.Fprintf(r.errOut, "  %s\n", line)
+		indent = 2
+	}
+	if column >= 0 {
+		fmt.Fprintf(r.errOut, "%s^\n", strings.Repeat(" ", indent+column))
+	}
+	fmt.Fprintf(r.errOut, "error: %v\n", err)
+}
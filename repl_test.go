@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runScript 把 script 作为非终端输入逐行交给 repl 执行
+func runScript(script string) (stdout, stderr string, code int) {
+	var out, errOut bytes.Buffer
+	code = newREPL(&out, &errOut).runLines(strings.NewReader(script))
+	return out.String(), errOut.String(), code
+}
+
+func TestREPL(t *testing.T) {
+	tests := []struct {
+		script string
+		stdout string
+	}{
+		{"1+2\n", "3\n"},
+		{"1+2\nans*2\nans+1\n", "3\n6\n7\n"},
+		// 赋值同时更新 ans
+		{"x = 5\nx^2 + ans\n", "x = 5\n30\n"},
+		{"r = 2\npi r^2\n:vars\n", "r = 2\n12.56637061435917295385057353311801\nans = 12.56637061435917295385057353311801\nr = 2\n"},
+		// 空行、注释和 quit
+		{"\n# note\n1\nquit\n2\n", "1\n"},
+		{"1\n:q\n2\n", "1\n"},
+		// :mode
+		{":mode\n", "mode basic\n"},
+		{":mode prog\n255\n-1\n10/3\n", "mode prog\n255 (hex FF)\n-1 (hex FFFF FFFF FFFF FFFF)\n3 (hex 3)\n"},
+		{":mode prog\n:mode basic\n10/4\n", "mode prog\nmode basic\n2.5\n"},
+		// :precision
+		{":precision\n", "precision 34\n"},
+		{":precision 5\n1/3\n2^40\n", "precision 5\n0.33333\n1.0995e+12\n"},
+		// :angle
+		{":angle\nsin(90)\n", "angle DEG\n1\n"},
+		{":angle rad\n:angle\ncos(0)\nsin(pi/2)\n", "angle RAD\nangle RAD\n1\n1\n"},
+		{":angle grad\nsin(100)\n", "angle GRAD\n1\n"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runScript(tt.script)
+		if stdout != tt.stdout || stderr != "" || code != exitOK {
+			t.Errorf("script %q: got %q, stderr %q, code %d; want %q", tt.script, stdout, stderr, code, tt.stdout)
+		}
+	}
+}
+
+func TestREPLErrors(t *testing.T) {
+	tests := []struct {
+		script string
+		stdout string
+		stderr string
+	}{
+		// 出错的行原样输出，^ 标在出错的位置下方
+		{"1 + * 2\n", "", "  1 + * 2\n      ^\nerror: syntax error at column 5: unexpected operator \"×\"\n"},
+		// 赋值时列号从等号后面算起
+		{"y = 2 +\n", "", "  y = 2 +\n         ^\nerror: syntax error at column 5: unexpected end of expression\n"},
+		{"1bad = 3\n", "", "error: invalid variable name \"1bad\": only letters are allowed\n"},
+		{"foo\n", "", "  foo\n  ^\nerror: syntax error at column 1: unknown variable \"foo\"\n"},
+		// 出错后继续执行后面的行，ans 保持不变
+		{"2\n1/0\nans\n", "2\n2\n", "error: division by zero\n"},
+		{":precision 0\n:precision\n", "precision 34\n", "error: invalid precision \"0\" (1 to 1000)\n"},
+		{":precision 1001\n", "", "error: invalid precision \"1001\" (1 to 1000)\n"},
+		{":angle foo\n", "", "error: invalid angle unit \"foo\"\n"},
+		{":mode foo\n:mode\n", "mode basic\n", "error: unknown mode \"foo\"\n"},
+		{":mode sci prog\n", "", "error: :mode takes at most one argument\n"},
+		{":frob\n", "", "error: unknown command :frob, :help lists the commands\n"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runScript(tt.script)
+		if stdout != tt.stdout || stderr != tt.stderr || code != exitError {
+			t.Errorf("script %q: got %q, stderr %q, code %d; want %q, stderr %q", tt.script, stdout, stderr, code, tt.stdout, tt.stderr)
+		}
+	}
+}
+
+// 终端中输入行已经显示在提示符后面，^ 的位置要加上提示符的宽度
+func TestREPLInteractiveCaret(t *testing.T) {
+	tests := []struct {
+		setup  string
+		line   string
+		stderr string
+	}{
+		{"", "1 + * 2", "           ^\nerror: syntax error at column 5: unexpected operator \"×\"\n"},
+		{"", "y = 2 +", "              ^\nerror: syntax error at column 5: unexpected end of expression\n"},
+		// 科学模式的提示符包含角度单位
+		{":mode sci", "1 + * 2", "             ^\nerror: syntax error at column 5: unexpected operator \"×\"\n"},
+		{"", "1/0", "error: division by zero\n"},
+	}
+	for _, tt := range tests {
+		var out, errOut bytes.Buffer
+		r := newREPL(&out, &errOut)
+		r.interactive = true
+		r.executeLine(tt.setup)
+		if ok, _ := r.executeLine(tt.line); ok {
+			t.Errorf("%q: succeeded", tt.line)
+		}
+		if errOut.String() != tt.stderr {
+			t.Errorf("%q after %q: stderr %q, want %q", tt.line, tt.setup, errOut.String(), tt.stderr)
+		}
+	}
+}
+
+func TestREPLPrompt(t *testing.T) {
+	r := newREPL(&bytes.Buffer{}, &bytes.Buffer{})
+	for _, tt := range []struct{ line, prompt string }{
+		{"", "basic> "},
+		{":mode sci", "sci DEG> "},
+		{":angle rad", "sci RAD> "},
+		{":mode prog", "prog> "},
+	} {
+		r.executeLine(tt.line)
+		if got := r.prompt(); got != tt.prompt {
+			t.Errorf("after %q: prompt %q, want %q", tt.line, got, tt.prompt)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gocalc/engine"
+	"gocalc/i18n"
+)
+
+// 服务模式的默认设置
+const (
+	defaultListen  = "127.0.0.1:8765"
+	defaultTimeout = 5 * time.Second
+	defaultMaxBody = 64 << 10
+)
+
+// apiError 结构化错误，以 {"error":{"code":...,"message":...}} 返回
+type apiError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Column  int    `json:"column,omitempty"` // 语法错误的字符列，从 1 开始
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+// invalidRequest 请求参数错误
+func invalidRequest(format string, args ...any) *apiError {
+	return &apiError{Status: http.StatusBadRequest, Code: "invalid_request", Message: fmt.Sprintf(format, args...)}
+}
+
+// errorCodes 计算错误类别对应的错误码，计算错误返回 422
+var errorCodes = map[engine.ErrorKind]string{
+	engine.KindDivideByZero:  "division_by_zero",
+	engine.KindDomain:        "domain_error",
+	engine.KindOverflow:      "overflow",
+	engine.KindPrecisionLoss: "precision_loss",
+}
+
+// toAPIError 把引擎错误转换为结构化错误
+func toAPIError(err error) *apiError {
+	var api *apiError
+	if errors.As(err, &api) {
+		return api
+	}
+	var syntax *engine.SyntaxError
+	if errors.As(err, &syntax) {
+		return &apiError{Status: http.StatusBadRequest, Code: "syntax_error", Message: err.Error(), Column: syntax.Pos + 1}
+	}
+	if errors.Is(err, engine.ErrAmountTooLarge) {
+		return &apiError{Status: http.StatusUnprocessableEntity, Code: "amount_too_large", Message: err.Error()}
+	}
+	if code, ok := errorCodes[engine.KindOf(err)]; ok {
+		return &apiError{Status: http.StatusUnprocessableEntity, Code: code, Message: err.Error()}
+	}
+	return &apiError{Status: http.StatusBadRequest, Code: "invalid_value", Message: err.Error()}
+}
+
+// server 本地计算服务，通过 HTTP JSON 提供 evaluate、convert 和 format 方法
+type server struct {
+	timeout time.Duration // 每个请求的最长计算时间
+	maxBody int64         // 请求体的最大字节数
+}
+
+// handler 服务的路由，每个方法一个 POST 路径，如 POST /evaluate
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/evaluate", endpoint(s, serveEvaluate))
+	mux.Handle("/convert", endpoint(s, serveConvert))
+	mux.Handle("/format", endpoint(s, serveFormat))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &apiError{Status: http.StatusNotFound, Code: "unknown_method", Message: fmt.Sprintf("unknown method %q", strings.TrimPrefix(r.URL.Path, "/"))})
+	})
+	return mux
+}
+
+// endpoint 把方法包装为 HTTP 处理函数：限制请求大小，解析 JSON，限制计算时间，返回结果或结构化错误
+func endpoint[Req any](s *server, call func(Req) (any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, &apiError{Status: http.StatusMethodNotAllowed, Code: "method_not_allowed", Message: "use POST"})
+			return
+		}
+		var req Req
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBody))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, &apiError{Status: http.StatusRequestEntityTooLarge, Code: "request_too_large", Message: fmt.Sprintf("request body exceeds %d bytes", s.maxBody)})
+				return
+			}
+			writeError(w, invalidRequest("invalid JSON: %v", err))
+			return
+		}
+
+		// 计算在单独的 goroutine 中进行，超时后立即返回错误；引擎不能中途取消，计算会在后台结束
+		ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+		defer cancel()
+		type result struct {
+			value any
+			err   error
+		}
+		done := make(chan result, 1)
+		go func() {
+			value, err := call(req)
+			done <- result{value, err}
+		}()
+		select {
+		case res := <-done:
+			if res.err != nil {
+				writeError(w, toAPIError(res.err))
+				return
+			}
+			writeJSON(w, http.StatusOK, res.value)
+		case <-ctx.Done():
+			writeError(w, &apiError{Status: http.StatusServiceUnavailable, Code: "timeout", Message: fmt.Sprintf("evaluation exceeded %s", s.timeout)})
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	writeJSON(w, err.Status, struct {
+		Error *apiError `json:"error"`
+	}{err})
+}
+
+// evaluateRequest evaluate 方法的参数
+type evaluateRequest struct {
+	Expression string            `json:"expression"`
+	Mode       string            `json:"mode"`      // basic（默认）、sci 或 prog，prog 按整数计算
+	Precision  int               `json:"precision"` // 有效数字位数，默认 34，最多 1000
+	Angle      string            `json:"angle"`     // deg（默认）、rad 或 grad
+	Word       int               `json:"word"`      // 程序员模式的字长：8、16、32 或 64（默认）
+	Unsigned   bool              `json:"unsigned"`  // 程序员模式按无符号数计算
+	Vars       map[string]string `json:"vars"`      // 表达式中可用的变量，值为十进制数
+}
+
+// evaluateResponse evaluate 方法的结果
+type evaluateResponse struct {
+	Result  string `json:"result"`  // 完整精度的十进制结果
+	Display string `json:"display"` // 窗口中显示的形式
+}
+
+// serveEvaluate evaluate 方法：计算表达式
+func serveEvaluate(req evaluateRequest) (any, error) {
+	if strings.TrimSpace(req.Expression) == "" {
+		return nil, invalidRequest("missing expression")
+	}
+	config := engine.DefaultConfig
+	if req.Mode != "" {
+		mode, err := engine.ParseMode(req.Mode)
+		if err != nil {
+			return nil, invalidRequest("%v", err)
+		}
+		config.Integer = mode == engine.ModeProgrammer
+	}
+	if req.Precision < 0 || req.Precision > engine.MaxPrecision {
+		return nil, invalidRequest("invalid precision %d (0 to %d)", req.Precision, engine.MaxPrecision)
+	}
+	config.Precision = req.Precision
+	if req.Angle != "" {
+		angle, ok := parseAngle(req.Angle)
+		if !ok {
+			return nil, invalidRequest("invalid angle unit %q", req.Angle)
+		}
+		config.Angle = angle
+	}
+	word, err := parseWord(req.Word, req.Unsigned)
+	if err != nil {
+		return nil, err
+	}
+	config.Word = word
+	if len(req.Vars) > 0 {
+		config.Vars = make(map[string]engine.Decimal, len(req.Vars))
+		for name, value := range req.Vars {
+			if err := engine.CheckVariable(name); err != nil {
+				return nil, invalidRequest("%v", err)
+			}
+			d, err := engine.ParseDecimal(value)
+			if err != nil {
+				return nil, invalidRequest("variable %s: %v", name, err)
+			}
+			config.Vars[name] = d
+		}
+	}
+	d, err := config.Eval(req.Expression)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateResponse{Result: d.String(), Display: engine.FormatNumber(d)}, nil
+}
+
+// convertRequest convert 方法的参数
+type convertRequest struct {
+	Value    string `json:"value"`    // 整数或整数表达式，可带 0x/0o/0b 前缀
+	From     int    `json:"from"`     // value 为不带前缀的 2、8 或 16 进制数字时的进制，默认按表达式解析
+	To       int    `json:"to"`       // 目标进制：2、8、10 或 16
+	Word     int    `json:"word"`     // 字长：8、16、32 或 64（默认），超出时截断
+	Unsigned bool   `json:"unsigned"` // 按无符号数解释
+}
+
+// convertResponse convert 方法的结果
+type convertResponse struct {
+	Result  string `json:"result"`  // 目标进制的数字，非十进制时为字长内的二进制补码
+	Decimal string `json:"decimal"` // 截断到字长后的十进制值
+}
+
+// serveConvert convert 方法：整数进制转换
+func serveConvert(req convertRequest) (any, error) {
+	if !validBase(req.To) {
+		return nil, invalidRequest("invalid target base %d", req.To)
+	}
+	word, err := parseWord(req.Word, req.Unsigned)
+	if err != nil {
+		return nil, err
+	}
+	var n *big.Int
+	switch {
+	case req.From == 0 || req.From == 10:
+		config := engine.Config{Integer: true, Word: word}
+		d, err := config.Eval(req.Value)
+		if err != nil {
+			return nil, err
+		}
+		n = d.BigInt()
+	case validBase(req.From):
+		var ok bool
+		n, ok = new(big.Int).SetString(strings.ReplaceAll(req.Value, " ", ""), req.From)
+		if !ok {
+			return nil, invalidRequest("invalid base %d number %q", req.From, req.Value)
+		}
+	default:
+		return nil, invalidRequest("invalid source base %d", req.From)
+	}
+	n = word.Wrap(n)
+	result := n.String()
+	if req.To != 10 {
+		result = strings.ToUpper(strconv.FormatUint(word.Pattern(n), req.To))
+	}
+	return convertResponse{Result: result, Decimal: n.String()}, nil
+}
+
+// formatRequest format 方法的参数
+type formatRequest struct {
+	Value     string `json:"value"`     // 十进制数，使用 '.' 小数点、不带分隔符
+	Style     string `json:"style"`     // display（默认，与窗口中相同）、full（完整精度）或 chinese（大写金额）
+	Locale    string `json:"locale"`    // 小数点和千位分隔符的语言区域，如 de-DE，默认 en
+	Grouping  string `json:"grouping"`  // thousands（默认）、myriad 或 none
+	MaxDigits int    `json:"maxDigits"` // display 最多显示的数字位数，默认 16
+	Notation  string `json:"notation"`  // display 超出位数时的记数法：scientific（默认）或 engineering
+}
+
+// formatResponse format 方法的结果
+type formatResponse struct {
+	Result string `json:"result"`
+}
+
+// serveFormat format 方法：按显示格式格式化数字
+func serveFormat(req formatRequest) (any, error) {
+	d, err := engine.ParseDecimal(req.Value)
+	if err != nil {
+		return nil, invalidRequest("%v", err)
+	}
+	f := engine.DefaultFormat
+	if req.Locale != "" {
+		locale := i18n.New(req.Locale)
+		f.Point, f.Group = locale.Point, locale.Group
+	}
+	switch req.Grouping {
+	case "", "thousands":
+	case "myriad":
+		f.Myriad = true
+	case "none":
+		f.Grouping = false
+	default:
+		return nil, invalidRequest("invalid grouping %q", req.Grouping)
+	}
+	if req.MaxDigits < 0 {
+		return nil, invalidRequest("invalid maxDigits %d", req.MaxDigits)
+	}
+	f.MaxDigits = req.MaxDigits
+	switch req.Notation {
+	case "", "scientific":
+	case "engineering":
+		f.Notation = engine.NotationEngineering
+	default:
+		return nil, invalidRequest("invalid notation %q", req.Notation)
+	}
+
+	var result string
+	switch req.Style {
+	case "", "display":
+		result = f.Decimal(d)
+	case "full":
+		result = f.Full(d)
+	case "chinese":
+		if result, err = engine.ChineseAmount(d); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, invalidRequest("invalid style %q", req.Style)
+	}
+	return formatResponse{Result: result}, nil
+}
+
+// parseWord 解析字长，0 表示 64 位
+func parseWord(bits int, unsigned bool) (engine.WordSize, error) {
+	switch bits {
+	case 0:
+		bits = engine.DefaultWordSize.Bits
+	case 8, 16, 32, 64:
+	default:
+		return engine.WordSize{}, invalidRequest("invalid word size %d", bits)
+	}
+	return engine.WordSize{Bits: bits, Signed: !unsigned}, nil
+}
+
+func validBase(base int) bool {
+	return base == 2 || base == 8 || base == 10 || base == 16
+}
+
+// runServe 运行 gocalc serve，直到收到中断信号，返回退出码
+func runServe(args []string, stderr io.Writer) int {
+	s := &server{}
+	var addr string
+	fs := flag.NewFlagSet("gocalc serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&addr, "listen", defaultListen, "`address` to listen on: host:port, or unix:/path/to/socket")
+	fs.DurationVar(&s.timeout, "timeout", defaultTimeout, "maximum evaluation `time` per request")
+	fs.Int64Var(&s.maxBody, "max-body", defaultMaxBody, "maximum request body size in `bytes`")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 || s.timeout <= 0 || s.maxBody <= 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	ln, err := listenAddr(addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "gocalc: %v\n", err)
+		return exitError
+	}
+	srv := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       s.timeout + 10*time.Second,
+		WriteTimeout:      s.timeout + 10*time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	log.Printf("gocalc serving on %s", ln.Addr())
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "gocalc: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// listenAddr 监听 TCP 地址或 unix:/path 形式的 Unix 套接字，套接字文件已存在时先删除
+func listenAddr(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	return net.Listen("unix", path)
+}
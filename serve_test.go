@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := &server{timeout: defaultTimeout, maxBody: 1 << 10}
+	ts := httptest.NewServer(s.handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestServe(t *testing.T) {
+	ts := newTestServer(t)
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		want   map[string]string // 期望的结果字段，出错时为 error.code
+	}{
+		{"/evaluate", `{"expression":"0.1 + 0.2"}`, http.StatusOK, map[string]string{"result": "0.3", "display": "0.3"}},
+		{"/evaluate", `{"expression":"2(3 + 4)"}`, http.StatusOK, map[string]string{"result": "14"}},
+		{"/evaluate", `{"expression":"x × 2","vars":{"x":"21"}}`, http.StatusOK, map[string]string{"result": "42"}},
+		{"/evaluate", `{"expression":"0xFF AND 0x0F","mode":"prog"}`, http.StatusOK, map[string]string{"result": "15"}},
+		{"/evaluate", `{"expression":"1 +"}`, http.StatusBadRequest, map[string]string{"code": "syntax_error"}},
+		{"/evaluate", `{"expression":"1 ÷ 0"}`, http.StatusUnprocessableEntity, map[string]string{"code": "division_by_zero"}},
+		{"/evaluate", `{"expression":"ln(-1)"}`, http.StatusUnprocessableEntity, map[string]string{"code": "domain_error"}},
+		{"/evaluate", `{"expression":"10 ^ 400000"}`, http.StatusUnprocessableEntity, map[string]string{"code": "overflow"}},
+		{"/evaluate", `{"expression":""}`, http.StatusBadRequest, map[string]string{"code": "invalid_request"}},
+		{"/evaluate", `{"expression":"1","mode":"hex"}`, http.StatusBadRequest, map[string]string{"code": "invalid_request"}},
+		{"/evaluate", `{"expression":"1/3","precision":300000000}`, http.StatusBadRequest, map[string]string{"code": "invalid_request"}},
+		{"/evaluate", `{"expression":"1/3","precision":5}`, http.StatusOK, map[string]string{"result": "0.33333"}},
+		{"/evaluate", `{"expr":"1"}`, http.StatusBadRequest, map[string]string{"code": "invalid_request"}},
+		{"/evaluate", `{"expression":`, http.StatusBadRequest, map[string]string{"code": "invalid_request"}},
+		{"/convert", `{"value":"255","to":16}`, http.StatusOK, map[string]string{"result": "FF", "decimal": "255"}},
+		{"/convert", `{"value":"-1","to":16,"word":8}`, http.StatusOK, map[string]string{"result": "FF", "decimal": "-1"}},
+		{"/convert", `{"value":"ff","from":16,"to":10,"word":8,"unsigned":true}`, http.StatusOK, map[string]string{"result": "255"}},
+		{"/convert", `{"value":"1","to":3}`, http.StatusBadRequest, map[string]string{"code": "invalid_request"}},
+		{"/format", `{"value":"1234567.891"}`, http.StatusOK, map[string]string{"result": "1,234,567.891"}},
+		{"/format", `{"value":"1234567.891","locale":"de-DE"}`, http.StatusOK, map[string]string{"result": "1.234.567,891"}},
+		{"/format", `{"value":"12345.67","style":"chinese"}`, http.StatusOK, map[string]string{"result": "壹万贰仟叁佰肆拾伍元陆角柒分"}},
+		{"/format", `{"value":"1e20","style":"chinese"}`, http.StatusUnprocessableEntity, map[string]string{"code": "amount_too_large"}},
+		{"/format", `{"value":"1","grouping":"lakh"}`, http.StatusBadRequest, map[string]string{"code": "invalid_request"}},
+		{"/sqrt", `{}`, http.StatusNotFound, map[string]string{"code": "unknown_method"}},
+	}
+	for _, tt := range tests {
+		resp, err := http.Post(ts.URL+tt.path, "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body struct {
+			Result  string
+			Display string
+			Decimal string
+			Error   *apiError
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("POST %s %s: invalid response: %v", tt.path, tt.body, err)
+			continue
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("POST %s %s: status %d, want %d", tt.path, tt.body, resp.StatusCode, tt.status)
+		}
+		got := map[string]string{"result": body.Result, "display": body.Display, "decimal": body.Decimal}
+		if body.Error != nil {
+			got = map[string]string{"code": body.Error.Code}
+		}
+		for field, want := range tt.want {
+			if got[field] != want {
+				t.Errorf("POST %s %s: %s = %q, want %q", tt.path, tt.body, field, got[field], want)
+			}
+		}
+	}
+}
+
+func TestServeSyntaxError(t *testing.T) {
+	ts := newTestServer(t)
+	tests := []struct {
+		body    string
+		column  int
+		message string
+	}{
+		{`{"expression":"1 + * 2"}`, 5, `unexpected operator "×"`},
+		{`{"expression":"ans + 1"}`, 1, `unknown variable or function "ans"`},
+		{`{"expression":"foo(2)"}`, 1, `unknown function "foo"`},
+		{`{"expression":"x + y","vars":{"x":"1"}}`, 5, `unknown variable "y"`},
+	}
+	for _, tt := range tests {
+		resp, err := http.Post(ts.URL+"/evaluate", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body struct{ Error apiError }
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body.Error.Code != "syntax_error" || body.Error.Column != tt.column || !strings.Contains(body.Error.Message, tt.message) {
+			t.Errorf("%s: got %q column %d %q; want syntax_error column %d %q", tt.body, body.Error.Code, body.Error.Column, body.Error.Message, tt.column, tt.message)
+		}
+	}
+}
+
+func TestServeMethodNotAllowed(t *testing.T) {
+	ts := newTestServer(t)
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		req, err := http.NewRequest(method, ts.URL+"/evaluate", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("%s /evaluate: status %d, want %d", method, resp.StatusCode, http.StatusMethodNotAllowed)
+		}
+		if allow := resp.Header.Get("Allow"); allow != http.MethodPost {
+			t.Errorf("%s /evaluate: Allow %q, want POST", method, allow)
+		}
+	}
+}
+
+func TestServeBodyTooLarge(t *testing.T) {
+	ts := newTestServer(t)
+	body := `{"expression":"` + strings.Repeat("1+", 1<<10) + `1"}`
+	resp, err := http.Post(ts.URL+"/evaluate", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestServeTimeout(t *testing.T) {
+	s := &server{timeout: time.Nanosecond, maxBody: defaultMaxBody}
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+	resp, err := http.Post(ts.URL+"/evaluate", "application/json", strings.NewReader(`{"expression":"9999!"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
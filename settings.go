@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+
+	"gocalc/engine"
+	"gocalc/i18n"
+)
+
+// settingsFileName 设置文件名，位于用户配置目录下的 gocalc 目录中
+const settingsFileName = "settings.json"
+
+// settings 用户设置，文件不存在或字段为空时使用默认值：
+//
+//	{"locale":"zh-CN","grouping":"myriad","theme":"light"}
+type settings struct {
+	Locale   string `json:"locale,omitempty"`   // 界面语言和数字格式，如 "de-DE"，为空时跟随系统
+	Grouping string `json:"grouping,omitempty"` // 整数分组：thousands（默认，1,234,567）或 myriad（123万4567）
+	Theme    string `json:"theme,omitempty"`    // 主题名，为空时使用默认的 dark 主题
+}
+
+// loadSettings 读取设置文件，文件有错误时记录日志并使用默认设置
+func loadSettings() settings {
+	var s settings
+	path, err := configPath(settingsFileName)
+	if err != nil {
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &s)
+	}
+	if err != nil {
+		log.Printf("settings %s ignored: %v", path, err)
+		return settings{}
+	}
+	return s
+}
+
+// saveSettings 保存设置文件，界面中修改的设置在下次启动时继续使用
+func saveSettings(s settings) error {
+	path, err := configPath(settingsFileName)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// format 按语言区域和分组设置确定数字的显示格式
+func (s settings) format(locale i18n.Locale) engine.Format {
+	format := engine.DefaultFormat
+	format.Point, format.Group = locale.Point, locale.Group
+	switch s.Grouping {
+	case "", "thousands":
+	case "myriad":
+		format.Myriad = true
+	default:
+		log.Printf("unknown grouping %q in settings", s.Grouping)
+	}
+	return format
+}
+
+// locale 设置的语言区域，未设置时读取系统的语言区域
+func (s settings) locale() i18n.Locale {
+	if s.Locale != "" {
+		return i18n.New(s.Locale)
+	}
+	return i18n.Detect()
+}
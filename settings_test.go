@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"gocalc/engine"
+	"gocalc/i18n"
+)
+
+func TestSettingsFormat(t *testing.T) {
+	tests := []struct {
+		settings settings
+		value    string
+		want     string
+	}{
+		{settings{}, "1234567.891", "1,234,567.891"},
+		{settings{Locale: "de-DE"}, "1234567.891", "1.234.567,891"},
+		{settings{Locale: "fr-FR"}, "1234567.891", "1\u00a0234\u00a0567,891"},
+		{settings{Locale: "de-CH"}, "1234567.891", "1'234'567.891"},
+		{settings{Locale: "zh-CN", Grouping: "myriad"}, "123456789.5", "1亿2345万6789.5"},
+		// 未知的分组设置使用默认值
+		{settings{Grouping: "lakh"}, "1234567", "1,234,567"},
+	}
+	for _, tt := range tests {
+		f := tt.settings.format(tt.settings.locale())
+		d, err := engine.ParseDecimal(tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.Decimal(d); got != tt.want {
+			t.Errorf("%+v: %s formatted as %q, want %q", tt.settings, tt.value, got, tt.want)
+		}
+	}
+}
+
+// 按语言区域显示和复制的数字可以原样粘贴回来
+func TestLocaleCopyPaste(t *testing.T) {
+	for _, tag := range []string{"en-US", "de-DE", "fr-FR", "de-CH"} {
+		locale := i18n.New(tag)
+		format := settings{}.format(locale)
+		e := engine.New()
+		e.SetFormat(format)
+		var s engine.State
+		for _, k := range []engine.Key{"1", "2", "3", "4", "5", "6", "7", engine.KeyDot, "8", "9", engine.KeyDiv, "2", engine.KeyEquals} {
+			s = e.Press(k)
+		}
+		if s.Err != nil {
+			t.Fatalf("%s: %v", tag, s.Err)
+		}
+		for _, grouped := range []bool{true, false} {
+			text := e.CopyText(grouped)
+			p := engine.New()
+			p.SetFormat(format)
+			got, err := p.Paste(text)
+			if err != nil {
+				t.Errorf("%s: pasting copied %q: %v", tag, text, err)
+				continue
+			}
+			if got.Display != s.Display {
+				t.Errorf("%s: copied %q pastes as %q, want %q", tag, text, got.Display, s.Display)
+			}
+		}
+	}
+}
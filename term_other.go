@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// makeRaw 其他系统不支持行编辑，REPL 逐行读取输入
+func makeRaw(in, out *os.File) (func(), error) {
+	return nil, errors.New("line editing is not supported on this system")
+}
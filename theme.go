@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"log"
+	"os"
+
+	"gioui.org/layout"
+	"gioui.org/widget/material"
+)
+
+// themesFileName 用户主题文件名，位于用户配置目录下的 gocalc 目录中
+const themesFileName = "themes.json"
+
+// Theme 界面配色，颜色按用途命名
+type Theme struct {
+	Name       string
+	Background color.NRGBA // 窗口背景
+	Surface    color.NRGBA // 按键、面板和菜单背景
+	Dialog     color.NRGBA // 关于窗口背景
+	Text       color.NRGBA // 主要文字
+	Secondary  color.NRGBA // 次要文字：之前的表达式、空列表提示
+	Accent     color.NRGBA // 运算符、选中项和成功提示
+	Equals     color.NRGBA // 等号按键背景
+	EqualsText color.NRGBA // 等号按键文字
+	Danger     color.NRGBA // 退格按键和错误提示
+	Disabled   color.NRGBA // 不可用的按键
+	Flash      color.NRGBA // 键盘按下时按键闪烁的颜色
+}
+
+// 内置主题
+var (
+	// darkTheme 默认的深绿色主题
+	darkTheme = Theme{
+		Name:       "dark",
+		Background: color.NRGBA{R: 30, G: 60, B: 50, A: 255},
+		Surface:    color.NRGBA{R: 40, G: 80, B: 65, A: 255},
+		Dialog:     color.NRGBA{R: 45, G: 75, B: 65, A: 255},
+		Text:       color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Secondary:  color.NRGBA{R: 200, G: 200, B: 200, A: 255},
+		Accent:     color.NRGBA{R: 0, G: 200, B: 100, A: 255},
+		Equals:     color.NRGBA{R: 0, G: 220, B: 110, A: 255},
+		EqualsText: color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Danger:     color.NRGBA{R: 255, G: 80, B: 80, A: 255},
+		Disabled:   color.NRGBA{R: 100, G: 130, B: 120, A: 255},
+		Flash:      color.NRGBA{R: 70, G: 140, B: 110, A: 255},
+	}
+	// lightTheme 浅色背景的绿色主题
+	lightTheme = Theme{
+		Name:       "light",
+		Background: color.NRGBA{R: 238, G: 243, B: 240, A: 255},
+		Surface:    color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Dialog:     color.NRGBA{R: 228, G: 236, B: 232, A: 255},
+		Text:       color.NRGBA{R: 25, G: 45, B: 38, A: 255},
+		Secondary:  color.NRGBA{R: 90, G: 110, B: 100, A: 255},
+		Accent:     color.NRGBA{R: 0, G: 130, B: 70, A: 255},
+		Equals:     color.NRGBA{R: 0, G: 150, B: 80, A: 255},
+		EqualsText: color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Danger:     color.NRGBA{R: 200, G: 40, B: 40, A: 255},
+		Disabled:   color.NRGBA{R: 165, G: 180, B: 172, A: 255},
+		Flash:      color.NRGBA{R: 190, G: 225, B: 205, A: 255},
+	}
+	// highContrastTheme 黑底白字的高对比度主题，强调色为黄色
+	highContrastTheme = Theme{
+		Name:       "high-contrast",
+		Background: color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+		Surface:    color.NRGBA{R: 32, G: 32, B: 32, A: 255},
+		Dialog:     color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+		Text:       color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Secondary:  color.NRGBA{R: 230, G: 230, B: 230, A: 255},
+		Accent:     color.NRGBA{R: 255, G: 220, B: 0, A: 255},
+		Equals:     color.NRGBA{R: 255, G: 220, B: 0, A: 255},
+		EqualsText: color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+		Danger:     color.NRGBA{R: 255, G: 110, B: 110, A: 255},
+		Disabled:   color.NRGBA{R: 140, G: 140, B: 140, A: 255},
+		Flash:      color.NRGBA{R: 100, G: 100, B: 100, A: 255},
+	}
+	// colorBlindTheme 使用 Okabe-Ito 配色的色盲友好主题，不依靠红绿区分按键
+	colorBlindTheme = Theme{
+		Name:       "colorblind",
+		Background: color.NRGBA{R: 28, G: 36, B: 48, A: 255},
+		Surface:    color.NRGBA{R: 42, G: 53, B: 68, A: 255},
+		Dialog:     color.NRGBA{R: 37, G: 48, B: 61, A: 255},
+		Text:       color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Secondary:  color.NRGBA{R: 200, G: 205, B: 212, A: 255},
+		Accent:     color.NRGBA{R: 86, G: 180, B: 233, A: 255},
+		Equals:     color.NRGBA{R: 230, G: 159, B: 0, A: 255},
+		EqualsText: color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+		Danger:     color.NRGBA{R: 240, G: 228, B: 66, A: 255},
+		Disabled:   color.NRGBA{R: 107, G: 119, B: 133, A: 255},
+		Flash:      color.NRGBA{R: 63, G: 88, B: 115, A: 255},
+	}
+)
+
+// builtinThemes 内置主题，第一个为默认主题
+var builtinThemes = []Theme{darkTheme, lightTheme, highContrastTheme, colorBlindTheme}
+
+// themesFile 用户主题文件内容，每个主题以 base 为基础（默认 dark），只需写出要修改的颜色：
+//
+//	{"themes":[{"name":"ocean","base":"dark","background":"#10283c","accent":"#4fc3f7"}]}
+type themesFile struct {
+	Themes []themeRecord `json:"themes"`
+}
+
+// themeRecord 文件中的一个主题，颜色为 #RRGGBB 或 #RRGGBBAA
+type themeRecord struct {
+	Name       string `json:"name"`
+	Base       string `json:"base"`
+	Background string `json:"background"`
+	Surface    string `json:"surface"`
+	Dialog     string `json:"dialog"`
+	Text       string `json:"text"`
+	Secondary  string `json:"secondary"`
+	Accent     string `json:"accent"`
+	Equals     string `json:"equals"`
+	EqualsText string `json:"equalsText"`
+	Danger     string `json:"danger"`
+	Disabled   string `json:"disabled"`
+	Flash      string `json:"flash"`
+}
+
+// loadThemes 内置主题加上用户主题文件中的主题，文件有错误时记录日志并只使用内置主题
+func loadThemes() []Theme {
+	path, err := configPath(themesFileName)
+	if err != nil {
+		return builtinThemes
+	}
+	themes, err := readThemes(path)
+	if err != nil {
+		log.Printf("themes %s ignored: %v", path, err)
+		return builtinThemes
+	}
+	return themes
+}
+
+// readThemes 读取并检查用户主题文件，与内置主题同名的主题替换内置主题
+func readThemes(path string) ([]Theme, error) {
+	themes := append([]Theme(nil), builtinThemes...)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return themes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file themesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for i, r := range file.Themes {
+		t, err := r.theme(themes)
+		if err != nil {
+			return nil, fmt.Errorf("theme %d: %w", i+1, err)
+		}
+		if j := findTheme(themes, t.Name); j >= 0 {
+			themes[j] = t
+		} else {
+			themes = append(themes, t)
+		}
+	}
+	return themes, nil
+}
+
+// theme 把文件中的主题转换为主题，base 可以是内置主题或文件中前面的主题
+func (r themeRecord) theme(themes []Theme) (Theme, error) {
+	if r.Name == "" {
+		return Theme{}, errors.New("missing name")
+	}
+	base := darkTheme.Name
+	if r.Base != "" {
+		base = r.Base
+	}
+	i := findTheme(themes, base)
+	if i < 0 {
+		return Theme{}, fmt.Errorf("unknown base theme %q", base)
+	}
+	t := themes[i]
+	t.Name = r.Name
+	for _, c := range []struct {
+		value string
+		color *color.NRGBA
+	}{
+		{r.Background, &t.Background},
+		{r.Surface, &t.Surface},
+		{r.Dialog, &t.Dialog},
+		{r.Text, &t.Text},
+		{r.Secondary, &t.Secondary},
+		{r.Accent, &t.Accent},
+		{r.Equals, &t.Equals},
+		{r.EqualsText, &t.EqualsText},
+		{r.Danger, &t.Danger},
+		{r.Disabled, &t.Disabled},
+		{r.Flash, &t.Flash},
+	} {
+		if c.value == "" {
+			continue
+		}
+		parsed, err := parseColor(c.value)
+		if err != nil {
+			return Theme{}, err
+		}
+		*c.color = parsed
+	}
+	return t, nil
+}
+
+// findTheme 按名字查找主题，找不到时返回 -1
+func findTheme(themes []Theme, name string) int {
+	for i, t := range themes {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// material 以主题颜色为默认文字和背景色的 material 主题
+func (t Theme) material() *material.Theme {
+	th := material.NewTheme()
+	th.Palette.Fg = t.Text
+	th.Palette.Bg = t.Background
+	th.Palette.ContrastBg = t.Accent
+	th.Palette.ContrastFg = t.EqualsText
+	return th
+}
+
+// keyColors 按键角色对应的背景色和文字颜色
+func (t Theme) keyColors(role keyRole) (bgColor, textColor color.NRGBA) {
+	switch role {
+	case roleEquals:
+		return t.Equals, t.EqualsText
+	case roleOperator:
+		return t.Surface, t.Accent
+	case roleBackspace:
+		return t.Surface, t.Danger
+	default:
+		return t.Surface, t.Text
+	}
+}
+
+// setTheme 切换到指定名字的主题，找不到时使用默认主题
+func (c *Calculator) setTheme(name string) {
+	c.palette = builtinThemes[0]
+	if i := findTheme(c.themes, name); i >= 0 {
+		c.palette = c.themes[i]
+	} else if name != "" {
+		log.Printf("unknown theme %q", name)
+	}
+	c.theme = c.palette.material()
+	c.invalidate()
+}
+
+// cycleTheme 切换到下一个主题并保存到设置文件；每次切换都重新读取用户主题文件，
+// 修改后的主题无需重启即可生效
+func (c *Calculator) cycleTheme(gtx layout.Context) {
+	c.themes = loadThemes()
+	next := 0
+	if i := findTheme(c.themes, c.palette.Name); i >= 0 {
+		next = (i + 1) % len(c.themes)
+	}
+	c.setTheme(c.themes[next].Name)
+	c.settings.Theme = c.palette.Name
+	if err := saveSettings(c.settings); err != nil {
+		log.Printf("save settings: %v", err)
+	}
+	c.showNotice(gtx, c.tr("Theme: %s", c.tr(c.palette.Name)), c.palette.Accent)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// layoutUndoKeys 撤销和重做按键，没有可撤销或重做的操作时变暗
+func (c *Calculator) layoutUndoKeys(gtx layout.Context) layout.Dimensions {
+	row := undoKeypad[0]
+	children := make([]layout.FlexChild, len(row))
+	for i := range row {
+		k := &row[i]
+		children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.clickable(k).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				l := material.Caption(c.theme, c.tr(k.label()))
+				l.Color = c.palette.Accent
+				if !c.keyEnabled(k) {
+					l.Color = c.palette.Disabled
+				}
+				l.Color = c.flashColor(gtx, k.name(), l.Color)
+				l.TextSize = unit.Sp(14)
+				return layout.Inset{Left: unit.Dp(15)}.Layout(gtx, l.Layout)
+			})
+		})
+	}
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+}